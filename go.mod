@@ -4,16 +4,26 @@ go 1.19
 
 require (
 	github.com/99designs/gqlgen v0.17.30
-	github.com/agnivade/levenshtein v1.1.1 // indirect
+	github.com/fasthttp/websocket v1.4.3-rc.6
 	github.com/gofiber/fiber/v2 v2.31.0
+	github.com/mitchellh/mapstructure v1.5.0
+	github.com/stretchr/testify v1.8.2
+	github.com/valyala/fasthttp v1.35.0
+	github.com/vektah/gqlparser/v2 v2.5.1
+)
+
+require (
+	github.com/agnivade/levenshtein v1.1.1 // indirect
+	github.com/andybalholm/brotli v1.0.4 // indirect
+	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/gorilla/websocket v1.5.0 // indirect
-	github.com/hashicorp/golang-lru v0.5.4 // indirect
+	github.com/hashicorp/golang-lru/v2 v2.0.1 // indirect
 	github.com/klauspost/compress v1.15.1 // indirect
-	github.com/mitchellh/mapstructure v1.4.3 // indirect
-	github.com/stretchr/testify v1.7.1
-	github.com/valyala/fasthttp v1.35.0 // indirect
-	github.com/vektah/gqlparser/v2 v2.4.1
-	golang.org/x/sys v0.0.0-20220408201424-a24fb2fb8a0f // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	github.com/savsgio/gotils v0.0.0-20210617111740-97865ed5a873 // indirect
+	github.com/valyala/bytebufferpool v1.0.0 // indirect
+	github.com/valyala/tcplisten v1.0.0 // indirect
+	golang.org/x/sys v0.5.0 // indirect
 	gopkg.in/yaml.v2 v2.4.0 // indirect
-	gopkg.in/yaml.v3 v3.0.0-20210107192922-496545a6307b // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
@@ -1,6 +1,8 @@
 package fibergqlgen
 
 import (
+	"context"
+
 	"github.com/99designs/gqlgen/graphql"
 	"github.com/gofiber/fiber/v2"
 )
@@ -11,4 +13,30 @@ type (
 		Supports(c *fiber.Ctx) bool
 		Do(c *fiber.Ctx, exec graphql.GraphExecutor) error
 	}
+
+	// TransportMethods is implemented by transports that know the HTTP methods they are served on, Server.Mount
+	// registers a route for those methods only
+	TransportMethods interface {
+		Methods() []string
+	}
+
+	// TransportValidator is implemented by transports with a configuration that can be invalid, Server.AddTransport
+	// panics with the error returned by Validate
+	TransportValidator interface {
+		Validate() error
+	}
 )
+
+type transportsKey struct{}
+
+// WithTransports returns a copy of ctx carrying the transports of the server handling the request, the
+// Options transport answers for the others with them
+func WithTransports(ctx context.Context, transports []Transport) context.Context {
+	return context.WithValue(ctx, transportsKey{}, transports)
+}
+
+// TransportsFromContext returns the transports of the server handling the request, or nil when there are none
+func TransportsFromContext(ctx context.Context) []Transport {
+	transports, _ := ctx.Value(transportsKey{}).([]Transport)
+	return transports
+}
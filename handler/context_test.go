@@ -0,0 +1,102 @@
+package handler_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql"
+	fibergqlgen "github.com/NickTaporuk/fiber-gqlgen"
+	"github.com/NickTaporuk/fiber-gqlgen/handler/testserver"
+	"github.com/NickTaporuk/fiber-gqlgen/handler/transport"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type ctxKey string
+
+func TestServeGraphQLUserContext(t *testing.T) {
+	var resolverCtx context.Context
+	srv := testserver.New()
+	srv.AddTransport(transport.GET{})
+	srv.AroundFields(func(ctx context.Context, next graphql.Resolver) (interface{}, error) {
+		resolverCtx = ctx
+		return next(ctx)
+	})
+
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		c.SetUserContext(context.WithValue(c.UserContext(), ctxKey("user"), "alice"))
+		return c.Next()
+	})
+	app.Get("/graphql", srv.ServeGraphQL)
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/graphql?query={name}", nil))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	require.NotNil(t, resolverCtx)
+	assert.Equal(t, "alice", resolverCtx.Value(ctxKey("user")))
+	assert.ErrorIs(t, resolverCtx.Err(), context.Canceled, "the context is cancelled once the response is complete")
+}
+
+func TestServeGraphQLCancellation(t *testing.T) {
+	var resolverErr error
+	srv := testserver.New()
+	srv.AddTransport(transport.GET{})
+	srv.AroundFields(func(ctx context.Context, next graphql.Resolver) (interface{}, error) {
+		select {
+		case <-ctx.Done():
+			resolverErr = ctx.Err()
+		case <-time.After(time.Second):
+		}
+		return next(ctx)
+	})
+
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		ctx, cancel := context.WithTimeout(c.UserContext(), 20*time.Millisecond)
+		defer cancel()
+		c.SetUserContext(ctx)
+		return c.Next()
+	})
+	app.Get("/graphql", srv.ServeGraphQL)
+
+	start := time.Now()
+	resp, err := app.Test(httptest.NewRequest("GET", "/graphql?query={name}", nil))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Less(t, time.Since(start), time.Second)
+	assert.ErrorIs(t, resolverErr, context.DeadlineExceeded, "the resolver is cancelled with the user context")
+}
+
+func TestServeGraphQLRequestInfo(t *testing.T) {
+	var info *fibergqlgen.RequestInfo
+	srv := testserver.New()
+	srv.AddTransport(transport.GET{})
+	srv.SetRequestInfoLocals("user")
+	srv.AroundFields(func(ctx context.Context, next graphql.Resolver) (interface{}, error) {
+		info = fibergqlgen.ForContext(ctx)
+		return next(ctx)
+	})
+
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("user", "alice")
+		return c.Next()
+	})
+	app.Get("/graphql", srv.ServeGraphQL)
+
+	req := httptest.NewRequest("GET", "/graphql?query={name}", nil)
+	req.Header.Set("X-Request-Id", "42")
+	_, err := app.Test(req)
+	require.NoError(t, err)
+
+	require.NotNil(t, info)
+	assert.Equal(t, "GET", info.Method())
+	assert.Equal(t, "42", info.Header("X-Request-Id"))
+	assert.Equal(t, "alice", info.Local("user"))
+}
@@ -0,0 +1,92 @@
+package extension
+
+import (
+	"context"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/99designs/gqlgen/graphql/errcode"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+const errIntrospectionDisabledCode = "INTROSPECTION_DISABLED"
+
+func init() {
+	errcode.RegisterErrorType(errIntrospectionDisabledCode, errcode.KindProtocol)
+}
+
+// IntrospectionPolicy decides per request whether the schema can be introspected. Operations selecting
+// __schema or __type are rejected with an INTROSPECTION_DISABLED error unless Allow returns true, however
+// the fields are aliased or nested in fragments. __typename is always allowed.
+//
+// NewDefaultServer adds a policy allowing every request, a policy added after it decides instead, eg to
+// allow introspection only with a header token:
+//
+//	srv.Use(extension.IntrospectionPolicy{
+//		Allow: func(ctx context.Context, rc *graphql.OperationContext) bool {
+//			return fibergqlgen.ForContext(ctx).Header("X-Introspection-Token") == token
+//		},
+//	})
+type IntrospectionPolicy struct {
+	// Allow returns true if the operation may introspect the schema, the request is available from
+	// fibergqlgen.ForContext. A nil Allow disables introspection for every request.
+	Allow func(ctx context.Context, rc *graphql.OperationContext) bool
+}
+
+var _ interface {
+	graphql.OperationContextMutator
+	graphql.HandlerExtension
+} = IntrospectionPolicy{}
+
+func (p IntrospectionPolicy) ExtensionName() string {
+	return "IntrospectionPolicy"
+}
+
+func (p IntrospectionPolicy) Validate(schema graphql.ExecutableSchema) error {
+	return nil
+}
+
+func (p IntrospectionPolicy) MutateOperationContext(ctx context.Context, rc *graphql.OperationContext) *gqlerror.Error {
+	allowed := p.Allow != nil && p.Allow(ctx, rc)
+	rc.DisableIntrospection = !allowed
+	if allowed || rc.Operation == nil {
+		return nil
+	}
+
+	field := introspectionField(rc.Operation.SelectionSet, map[*ast.FragmentDefinition]bool{})
+	if field == nil {
+		return nil
+	}
+
+	err := gqlerror.Errorf("introspection is not allowed, but the operation selects %s", field.Name)
+	errcode.Set(err, errIntrospectionDisabledCode)
+	return at(err, field.Position)
+}
+
+// introspectionField returns the first __schema or __type field selected in set, visited guards against
+// fragment cycles
+func introspectionField(set ast.SelectionSet, visited map[*ast.FragmentDefinition]bool) *ast.Field {
+	for _, sel := range set {
+		switch sel := sel.(type) {
+		case *ast.Field:
+			if sel.Name == "__schema" || sel.Name == "__type" {
+				return sel
+			}
+			if field := introspectionField(sel.SelectionSet, visited); field != nil {
+				return field
+			}
+		case *ast.InlineFragment:
+			if field := introspectionField(sel.SelectionSet, visited); field != nil {
+				return field
+			}
+		case *ast.FragmentSpread:
+			if def := sel.Definition; def != nil && !visited[def] {
+				visited[def] = true
+				if field := introspectionField(def.SelectionSet, visited); field != nil {
+					return field
+				}
+			}
+		}
+	}
+	return nil
+}
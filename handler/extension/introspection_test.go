@@ -0,0 +1,129 @@
+package extension_test
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/99designs/gqlgen/graphql"
+	fibergqlgen "github.com/NickTaporuk/fiber-gqlgen"
+	"github.com/NickTaporuk/fiber-gqlgen/handler"
+	"github.com/NickTaporuk/fiber-gqlgen/handler/extension"
+	"github.com/NickTaporuk/fiber-gqlgen/handler/testserver"
+	"github.com/NickTaporuk/fiber-gqlgen/handler/transport"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vektah/gqlparser/v2"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+func TestIntrospectionPolicy(t *testing.T) {
+	check := func(t *testing.T, policy extension.IntrospectionPolicy, query string) (*graphql.OperationContext, string) {
+		t.Helper()
+		rc := &graphql.OperationContext{RawQuery: query}
+		doc, errs := gqlparser.LoadQuery(limitsSchema, query)
+		require.Nil(t, errs)
+		rc.Doc = doc
+		rc.Operation = doc.Operations[0]
+
+		err := policy.MutateOperationContext(context.Background(), rc)
+		if err == nil {
+			return rc, ""
+		}
+		assert.Equal(t, "INTROSPECTION_DISABLED", err.Extensions["code"])
+		return rc, err.Error()
+	}
+
+	t.Run("denied", func(t *testing.T) {
+		for name, query := range map[string]string{
+			"schema":          `{ __schema { queryType { name } } }`,
+			"type":            `{ name __type(name: "User") { name } }`,
+			"aliased":         `{ s: __schema { queryType { name } } }`,
+			"inline fragment": `{ ... on Query { __schema { queryType { name } } } }`,
+			"fragment":        `query { ...F } fragment F on Query { user { name } ...G } fragment G on Query { t: __type(name: "User") { name } }`,
+		} {
+			t.Run(name, func(t *testing.T) {
+				rc, msg := check(t, extension.IntrospectionPolicy{}, query)
+				assert.Contains(t, msg, "introspection is not allowed, but the operation selects __")
+				assert.True(t, rc.DisableIntrospection)
+			})
+		}
+	})
+
+	t.Run("typename is not introspection", func(t *testing.T) {
+		_, msg := check(t, extension.IntrospectionPolicy{}, `{ __typename user { __typename name } }`)
+		assert.Empty(t, msg)
+	})
+
+	t.Run("allowed", func(t *testing.T) {
+		rc, msg := check(t, extension.IntrospectionPolicy{
+			Allow: func(ctx context.Context, rc *graphql.OperationContext) bool { return true },
+		}, `{ __schema { queryType { name } } }`)
+		assert.Empty(t, msg)
+		assert.False(t, rc.DisableIntrospection)
+	})
+
+	t.Run("policy on the request", func(t *testing.T) {
+		h := testserver.New()
+		h.AddTransport(transport.POST{})
+		h.Use(extension.IntrospectionPolicy{
+			Allow: func(ctx context.Context, rc *graphql.OperationContext) bool {
+				return fibergqlgen.ForContext(ctx).Header("X-Introspection-Token") == "secret"
+			},
+		})
+
+		app := fiber.New()
+		app.Post("/graphql", h.ServeGraphQL)
+
+		do := func(t *testing.T, token string) (int, string) {
+			req := httptest.NewRequest("POST", "/graphql", strings.NewReader(`{"query":"{ __type(name: \"Query\") { name } }"}`))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("X-Introspection-Token", token)
+			resp, err := app.Test(req)
+			require.NoError(t, err)
+			b, err := ioutil.ReadAll(resp.Body)
+			require.NoError(t, err)
+			return resp.StatusCode, string(b)
+		}
+
+		code, body := do(t, "wrong")
+		assert.Equal(t, http.StatusUnprocessableEntity, code)
+		assert.Equal(t, `{"errors":[{"message":"introspection is not allowed, but the operation selects __type","locations":[{"line":1,"column":3}],"extensions":{"code":"INTROSPECTION_DISABLED"}}],"data":null}`, body)
+
+		code, _ = do(t, "secret")
+		assert.Equal(t, http.StatusOK, code)
+	})
+
+	t.Run("default server", func(t *testing.T) {
+		do := func(t *testing.T, h *handler.Server) (bool, int) {
+			disabled := true
+			h.AroundOperations(func(ctx context.Context, next graphql.OperationHandler) graphql.ResponseHandler {
+				disabled = graphql.GetOperationContext(ctx).DisableIntrospection
+				return graphql.OneShot(&graphql.Response{Data: []byte(`{}`)})
+			})
+
+			app := fiber.New()
+			app.Post("/graphql", h.ServeGraphQL)
+
+			req := httptest.NewRequest("POST", "/graphql", strings.NewReader(`{"query":"{ __schema { queryType { name } } }"}`))
+			req.Header.Set("Content-Type", "application/json")
+			resp, err := app.Test(req)
+			require.NoError(t, err)
+			return disabled, resp.StatusCode
+		}
+		schema := &graphql.ExecutableSchemaMock{SchemaFunc: func() *ast.Schema { return limitsSchema }}
+
+		disabled, code := do(t, handler.NewDefaultServer(schema))
+		assert.Equal(t, http.StatusOK, code)
+		assert.False(t, disabled, "introspection is allowed by default")
+
+		h := handler.NewDefaultServer(schema)
+		h.Use(extension.IntrospectionPolicy{})
+		_, code = do(t, h)
+		assert.Equal(t, http.StatusUnprocessableEntity, code, "a policy added later decides")
+	})
+}
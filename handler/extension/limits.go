@@ -0,0 +1,284 @@
+package extension
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/99designs/gqlgen/graphql/errcode"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+	"github.com/vektah/gqlparser/v2/lexer"
+)
+
+const (
+	errQueryTooLongCode      = "QUERY_TOO_LONG"
+	errTooManyTokensCode     = "TOO_MANY_TOKENS"
+	errMaxDepthExceededCode  = "MAX_DEPTH_EXCEEDED"
+	errTooManyAliasesCode    = "TOO_MANY_ALIASES"
+	errTooManyRootFieldsCode = "TOO_MANY_ROOT_FIELDS"
+	errTooManyDirectivesCode = "TOO_MANY_DIRECTIVES"
+)
+
+func init() {
+	// like validation errors they are answered with 422 by the http transports
+	for _, code := range []string{
+		errQueryTooLongCode,
+		errTooManyTokensCode,
+		errMaxDepthExceededCode,
+		errTooManyAliasesCode,
+		errTooManyRootFieldsCode,
+		errTooManyDirectivesCode,
+	} {
+		errcode.RegisterErrorType(code, errcode.KindProtocol)
+	}
+}
+
+// Limits rejects documents that are too big or too deep to be executed cheaply. Every limit left at
+// zero is not enforced.
+//
+// The query length and token limits are checked before the document is parsed, lexing stops at the
+// first token over the limit. Add Limits after AutomaticPersistedQuery and TrustedDocuments so the
+// query they resolve from a hash is checked too.
+type Limits struct {
+	// MaxQueryLength is the maximum length of the query in bytes.
+	MaxQueryLength int
+
+	// MaxTokens is the maximum number of lexical tokens of the query.
+	MaxTokens int
+
+	// MaxDepth is the maximum nesting of fields in the operation, a field without a selection is at depth 1.
+	MaxDepth int
+
+	// MaxAliases is the maximum number of aliased fields in the operation, fragments count each time they are spread.
+	MaxAliases int
+
+	// MaxRootFields is the maximum number of fields selected at the root of the operation.
+	MaxRootFields int
+
+	// MaxDirectivesPerField is the maximum number of directives on a single field.
+	MaxDirectivesPerField int
+}
+
+var _ interface {
+	graphql.OperationParameterMutator
+	graphql.OperationContextMutator
+	graphql.HandlerExtension
+} = Limits{}
+
+func (l Limits) ExtensionName() string {
+	return "Limits"
+}
+
+func (l Limits) Validate(schema graphql.ExecutableSchema) error {
+	if l.MaxQueryLength < 0 || l.MaxTokens < 0 || l.MaxDepth < 0 || l.MaxAliases < 0 || l.MaxRootFields < 0 || l.MaxDirectivesPerField < 0 {
+		return fmt.Errorf("Limits can not be negative")
+	}
+	return nil
+}
+
+func (l Limits) MutateOperationParameters(ctx context.Context, rawParams *graphql.RawParams) *gqlerror.Error {
+	if l.MaxQueryLength != 0 && len(rawParams.Query) > l.MaxQueryLength {
+		return limitError(errQueryTooLongCode, "query length %d exceeds the maximum of %d", len(rawParams.Query), l.MaxQueryLength)
+	}
+
+	if l.MaxTokens != 0 {
+		lex := lexer.New(&ast.Source{Input: rawParams.Query})
+		for tokens := 0; ; tokens++ {
+			token, err := lex.ReadToken()
+			if err != nil || token.Kind == lexer.EOF {
+				// syntax errors are reported by the parser
+				break
+			}
+			if tokens == l.MaxTokens {
+				return limitError(errTooManyTokensCode, "query exceeds the maximum of %d tokens", l.MaxTokens)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (l Limits) MutateOperationContext(ctx context.Context, rc *graphql.OperationContext) *gqlerror.Error {
+	if rc.Operation == nil {
+		return nil
+	}
+
+	w := limitsWalker{
+		limits:    l,
+		fragments: map[*ast.FragmentDefinition]*fragmentSummary{},
+		visited:   map[*ast.FragmentDefinition]bool{},
+	}
+	if l.MaxRootFields != 0 {
+		if n := w.summarize(rc.Operation.SelectionSet).rootFields; n > l.MaxRootFields {
+			return limitError(errTooManyRootFieldsCode, "operation selects %d root fields, the maximum is %d", n, l.MaxRootFields)
+		}
+	}
+
+	w.walk(rc.Operation.SelectionSet, 1)
+	return w.err
+}
+
+// fragmentSummary holds the totals of a selection set, fragments are summarized once so a document
+// spreading the same fragments over and over is checked in linear time
+type fragmentSummary struct {
+	// depth is the nesting of the deepest field, a field without a selection is at depth 1
+	depth int
+	// aliases is the number of aliased fields, fragments count each time they are spread
+	aliases int
+	// rootFields is the number of fields selected at the top of the set
+	rootFields int
+	// directives is set when a field has more directives than allowed
+	directives bool
+}
+
+type limitsWalker struct {
+	limits  Limits
+	aliases int
+	// fragments memoizes the summary of every fragment
+	fragments map[*ast.FragmentDefinition]*fragmentSummary
+	// visited tracks the fragments on the current path, validation rejects cycles but the walk
+	// should not depend on it
+	visited map[*ast.FragmentDefinition]bool
+	err     *gqlerror.Error
+}
+
+func (w *limitsWalker) summarize(set ast.SelectionSet) fragmentSummary {
+	var s fragmentSummary
+	for _, sel := range set {
+		switch sel := sel.(type) {
+		case *ast.Field:
+			sub := w.summarize(sel.SelectionSet)
+			s.depth = maxInt(s.depth, sub.depth+1)
+			if sel.Alias != "" && sel.Alias != sel.Name {
+				s.aliases = add(s.aliases, 1)
+			}
+			s.aliases = add(s.aliases, sub.aliases)
+			s.rootFields = add(s.rootFields, 1)
+			s.directives = s.directives || sub.directives ||
+				w.limits.MaxDirectivesPerField != 0 && len(sel.Directives) > w.limits.MaxDirectivesPerField
+		case *ast.InlineFragment:
+			s.merge(w.summarize(sel.SelectionSet))
+		case *ast.FragmentSpread:
+			if def := sel.Definition; def != nil {
+				s.merge(w.fragment(def))
+			}
+		}
+	}
+	return s
+}
+
+// fragment returns the memoized summary of def, a fragment spreading itself counts as empty
+func (w *limitsWalker) fragment(def *ast.FragmentDefinition) fragmentSummary {
+	if s, ok := w.fragments[def]; ok {
+		return *s
+	}
+	if w.visited[def] {
+		return fragmentSummary{}
+	}
+
+	w.visited[def] = true
+	s := w.summarize(def.SelectionSet)
+	w.visited[def] = false
+
+	w.fragments[def] = &s
+	return s
+}
+
+func (s *fragmentSummary) merge(other fragmentSummary) {
+	s.depth = maxInt(s.depth, other.depth)
+	s.aliases = add(s.aliases, other.aliases)
+	s.rootFields = add(s.rootFields, other.rootFields)
+	s.directives = s.directives || other.directives
+}
+
+// exceeds reports whether spreading a fragment summarized by s at depth exceeds a limit
+func (w *limitsWalker) exceeds(s fragmentSummary, depth int) bool {
+	l := w.limits
+	return l.MaxDepth != 0 && depth-1+s.depth > l.MaxDepth ||
+		l.MaxAliases != 0 && add(w.aliases, s.aliases) > l.MaxAliases ||
+		s.directives
+}
+
+// walk visits the fields of set at depth and stops at the first limit exceeded. Fragments within the
+// limits are accounted for by their summary, only a fragment exceeding one is walked to find the field
+// to report.
+func (w *limitsWalker) walk(set ast.SelectionSet, depth int) {
+	for _, sel := range set {
+		if w.err != nil {
+			return
+		}
+
+		switch sel := sel.(type) {
+		case *ast.Field:
+			w.field(sel, depth)
+		case *ast.InlineFragment:
+			w.walk(sel.SelectionSet, depth)
+		case *ast.FragmentSpread:
+			def := sel.Definition
+			if def == nil || w.visited[def] {
+				continue
+			}
+			if s := w.fragment(def); !w.exceeds(s, depth) {
+				w.aliases = add(w.aliases, s.aliases)
+				continue
+			}
+			w.visited[def] = true
+			w.walk(def.SelectionSet, depth)
+			w.visited[def] = false
+		}
+	}
+}
+
+func (w *limitsWalker) field(field *ast.Field, depth int) {
+	l := w.limits
+
+	if l.MaxDepth != 0 && depth > l.MaxDepth {
+		w.err = at(limitError(errMaxDepthExceededCode, "field %s exceeds the maximum depth of %d", field.Alias, l.MaxDepth), field.Position)
+		return
+	}
+
+	if field.Alias != "" && field.Alias != field.Name {
+		w.aliases++
+		if l.MaxAliases != 0 && w.aliases > l.MaxAliases {
+			w.err = at(limitError(errTooManyAliasesCode, "operation exceeds the maximum of %d aliases", l.MaxAliases), field.Position)
+			return
+		}
+	}
+
+	if l.MaxDirectivesPerField != 0 && len(field.Directives) > l.MaxDirectivesPerField {
+		w.err = at(limitError(errTooManyDirectivesCode, "field %s has %d directives, the maximum is %d", field.Alias, len(field.Directives), l.MaxDirectivesPerField), field.Position)
+		return
+	}
+
+	w.walk(field.SelectionSet, depth+1)
+}
+
+func at(err *gqlerror.Error, pos *ast.Position) *gqlerror.Error {
+	if pos != nil {
+		err.Locations = []gqlerror.Location{{Line: pos.Line, Column: pos.Column}}
+	}
+	return err
+}
+
+func limitError(code string, format string, args ...interface{}) *gqlerror.Error {
+	err := gqlerror.Errorf(format, args...)
+	errcode.Set(err, code)
+	return err
+}
+
+// add returns a+b, saturating instead of overflowing on the totals of fragments spread exponentially often
+func add(a, b int) int {
+	if a > math.MaxInt-b {
+		return math.MaxInt
+	}
+	return a + b
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
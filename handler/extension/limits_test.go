@@ -0,0 +1,162 @@
+package extension_test
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/NickTaporuk/fiber-gqlgen/handler/extension"
+	"github.com/NickTaporuk/fiber-gqlgen/handler/testserver"
+	"github.com/NickTaporuk/fiber-gqlgen/handler/transport"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vektah/gqlparser/v2"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+var limitsSchema = gqlparser.MustLoadSchema(&ast.Source{Input: `
+	directive @a on FIELD
+	directive @b on FIELD
+	type Query {
+		user: User
+		name: String
+	}
+	type User {
+		name: String
+		friend: User
+	}
+`})
+
+func TestLimits(t *testing.T) {
+	check := func(t *testing.T, limits extension.Limits, query string) (string, string) {
+		t.Helper()
+		rc := &graphql.OperationContext{RawQuery: query}
+		doc, errs := gqlparser.LoadQuery(limitsSchema, query)
+		require.Nil(t, errs)
+		rc.Doc = doc
+		rc.Operation = doc.Operations[0]
+
+		err := limits.MutateOperationContext(context.Background(), rc)
+		if err == nil {
+			return "", ""
+		}
+		return err.Message, err.Extensions["code"].(string)
+	}
+
+	t.Run("depth", func(t *testing.T) {
+		query := `{ user { friend { friend { name } } } }`
+
+		msg, _ := check(t, extension.Limits{MaxDepth: 4}, query)
+		assert.Empty(t, msg)
+
+		msg, code := check(t, extension.Limits{MaxDepth: 3}, query)
+		assert.Equal(t, "field name exceeds the maximum depth of 3", msg)
+		assert.Equal(t, "MAX_DEPTH_EXCEEDED", code)
+	})
+
+	t.Run("depth through fragments", func(t *testing.T) {
+		query := `{ user { ...F } } fragment F on User { friend { ... on User { friend { name } } } }`
+
+		msg, code := check(t, extension.Limits{MaxDepth: 3}, query)
+		assert.Equal(t, "field name exceeds the maximum depth of 3", msg)
+		assert.Equal(t, "MAX_DEPTH_EXCEEDED", code)
+	})
+
+	t.Run("aliases", func(t *testing.T) {
+		query := `{ a: name b: name user { ...F } } fragment F on User { c: name }`
+
+		msg, _ := check(t, extension.Limits{MaxAliases: 3}, query)
+		assert.Empty(t, msg)
+
+		msg, code := check(t, extension.Limits{MaxAliases: 2}, query)
+		assert.Equal(t, "operation exceeds the maximum of 2 aliases", msg)
+		assert.Equal(t, "TOO_MANY_ALIASES", code)
+	})
+
+	t.Run("root fields", func(t *testing.T) {
+		query := `{ name user { name } ... on Query { a: name } }`
+
+		msg, _ := check(t, extension.Limits{MaxRootFields: 3}, query)
+		assert.Empty(t, msg)
+
+		msg, code := check(t, extension.Limits{MaxRootFields: 2}, query)
+		assert.Equal(t, "operation selects 3 root fields, the maximum is 2", msg)
+		assert.Equal(t, "TOO_MANY_ROOT_FIELDS", code)
+	})
+
+	t.Run("directives per field", func(t *testing.T) {
+		query := `{ name @a @b }`
+
+		msg, _ := check(t, extension.Limits{MaxDirectivesPerField: 2}, query)
+		assert.Empty(t, msg)
+
+		msg, code := check(t, extension.Limits{MaxDirectivesPerField: 1}, query)
+		assert.Equal(t, "field name has 2 directives, the maximum is 1", msg)
+		assert.Equal(t, "TOO_MANY_DIRECTIVES", code)
+	})
+
+	t.Run("fragments spread exponentially often", func(t *testing.T) {
+		// each fragment spreads the previous one twice, the operation selects a: name 2^30 times
+		var query strings.Builder
+		query.WriteString(`{ ...F30 } fragment F0 on Query { a: name }`)
+		for i := 1; i <= 30; i++ {
+			fmt.Fprintf(&query, ` fragment F%d on Query { ...F%d ...F%d }`, i, i-1, i-1)
+		}
+
+		start := time.Now()
+		msg, _ := check(t, extension.Limits{MaxDepth: 1, MaxRootFields: 1 << 30, MaxAliases: 1 << 30}, query.String())
+		assert.Empty(t, msg)
+
+		msg, code := check(t, extension.Limits{MaxRootFields: 1 << 29}, query.String())
+		assert.Equal(t, fmt.Sprintf("operation selects %d root fields, the maximum is %d", 1<<30, 1<<29), msg)
+		assert.Equal(t, "TOO_MANY_ROOT_FIELDS", code)
+
+		msg, code = check(t, extension.Limits{MaxAliases: 1 << 29}, query.String())
+		assert.Equal(t, fmt.Sprintf("operation exceeds the maximum of %d aliases", 1<<29), msg)
+		assert.Equal(t, "TOO_MANY_ALIASES", code)
+		assert.Less(t, time.Since(start), time.Second)
+	})
+
+	t.Run("before parsing", func(t *testing.T) {
+		do := func(t *testing.T, limits extension.Limits, query string) (int, string) {
+			h := testserver.New()
+			h.AddTransport(transport.GRAPHQL{})
+			h.Use(limits)
+
+			app := fiber.New()
+			app.Post("/graphql", h.ServeGraphQL)
+
+			req := httptest.NewRequest("POST", "/graphql", strings.NewReader(query))
+			req.Header.Set("Content-Type", "application/graphql")
+			resp, err := app.Test(req)
+			require.NoError(t, err)
+			b, err := ioutil.ReadAll(resp.Body)
+			require.NoError(t, err)
+			return resp.StatusCode, string(b)
+		}
+
+		code, body := do(t, extension.Limits{MaxTokens: 3}, `{ name }`)
+		assert.Equal(t, http.StatusOK, code)
+		assert.Equal(t, `{"data":{"name":"test"}}`, body)
+
+		code, body = do(t, extension.Limits{MaxTokens: 2}, `{ name }`)
+		assert.Equal(t, http.StatusUnprocessableEntity, code)
+		assert.Equal(t, `{"errors":[{"message":"query exceeds the maximum of 2 tokens","extensions":{"code":"TOO_MANY_TOKENS"}}],"data":null}`, body)
+
+		// the document is never parsed, its syntax errors are not reported
+		code, body = do(t, extension.Limits{MaxTokens: 2}, `{ name } } } }`)
+		assert.Equal(t, http.StatusUnprocessableEntity, code)
+		assert.Contains(t, body, "TOO_MANY_TOKENS")
+
+		code, body = do(t, extension.Limits{MaxQueryLength: 5}, `{ name }`)
+		assert.Equal(t, http.StatusUnprocessableEntity, code)
+		assert.Equal(t, `{"errors":[{"message":"query length 8 exceeds the maximum of 5","extensions":{"code":"QUERY_TOO_LONG"}}],"data":null}`, body)
+	})
+}
@@ -0,0 +1,137 @@
+package extension
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+)
+
+// Manifest holds the trusted documents by their id.
+//
+// LoadManifest reads the manifests written by the common client tooling:
+//   - the Apollo persisted query manifest, {"format":"apollo-persisted-query-manifest","operations":[{"id":"…","body":"…"}]}
+//   - the Relay persisted queries map, {"<hash>":"<document>"}
+//   - a list of documentId entries, [{"documentId":"sha256:…","document":"…"}], optionally wrapped in {"documents":[…]}
+type Manifest struct {
+	documents map[string]string
+	ids       map[string]string
+}
+
+// NewManifest creates a manifest from a map of id to document
+func NewManifest(documents map[string]string) *Manifest {
+	m := &Manifest{
+		documents: make(map[string]string, len(documents)),
+		ids:       make(map[string]string, len(documents)),
+	}
+	for id, document := range documents {
+		m.add(id, document)
+	}
+	return m
+}
+
+// LoadManifestFile reads a manifest from the file at path
+func LoadManifestFile(path string) (*Manifest, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return LoadManifest(f)
+}
+
+// LoadManifest reads a manifest in any of the supported formats from r
+func LoadManifest(r io.Reader) (*Manifest, error) {
+	var raw json.RawMessage
+	if err := json.NewDecoder(r).Decode(&raw); err != nil {
+		return nil, fmt.Errorf("manifest could not be decoded: %w", err)
+	}
+
+	var list []documentIDEntry
+	if err := json.Unmarshal(raw, &list); err == nil {
+		return manifestFromList(list)
+	}
+
+	var object map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &object); err != nil {
+		return nil, fmt.Errorf("manifest must be a JSON object or array")
+	}
+
+	if format, ok := object["format"]; ok {
+		var apollo struct {
+			Format     string `json:"format"`
+			Operations []struct {
+				ID   string `json:"id"`
+				Body string `json:"body"`
+			} `json:"operations"`
+		}
+		if err := json.Unmarshal(raw, &apollo); err != nil {
+			return nil, fmt.Errorf("apollo manifest could not be decoded: %w", err)
+		}
+		if apollo.Format != "apollo-persisted-query-manifest" {
+			return nil, fmt.Errorf("unsupported manifest format %s", format)
+		}
+
+		m := NewManifest(nil)
+		for _, op := range apollo.Operations {
+			if op.ID == "" {
+				return nil, fmt.Errorf("apollo manifest operation without id")
+			}
+			m.add(op.ID, op.Body)
+		}
+		return m, nil
+	}
+
+	if documents, ok := object["documents"]; ok {
+		if err := json.Unmarshal(documents, &list); err != nil {
+			return nil, fmt.Errorf("documents could not be decoded: %w", err)
+		}
+		return manifestFromList(list)
+	}
+
+	var relay map[string]string
+	if err := json.Unmarshal(raw, &relay); err != nil {
+		return nil, fmt.Errorf("relay manifest could not be decoded: %w", err)
+	}
+	return NewManifest(relay), nil
+}
+
+type documentIDEntry struct {
+	DocumentID string `json:"documentId"`
+	Document   string `json:"document"`
+}
+
+func manifestFromList(list []documentIDEntry) (*Manifest, error) {
+	m := NewManifest(nil)
+	for _, entry := range list {
+		if entry.DocumentID == "" {
+			return nil, fmt.Errorf("manifest document without documentId")
+		}
+		m.add(entry.DocumentID, entry.Document)
+	}
+	return m, nil
+}
+
+func (m *Manifest) add(id string, document string) {
+	id = normalizeDocumentID(id)
+	m.documents[id] = document
+	m.ids[computeDocumentHash(document)] = id
+}
+
+// Get returns the document registered under id
+func (m *Manifest) Get(id string) (string, bool) {
+	document, ok := m.documents[normalizeDocumentID(id)]
+	return document, ok
+}
+
+// Lookup returns the id of a registered document that is exactly the given document
+func (m *Manifest) Lookup(document string) (string, bool) {
+	id, ok := m.ids[computeDocumentHash(document)]
+	return id, ok
+}
+
+// Len returns the number of registered documents
+func (m *Manifest) Len() int {
+	return len(m.documents)
+}
@@ -0,0 +1,59 @@
+package extension_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/NickTaporuk/fiber-gqlgen/handler/extension"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadManifest(t *testing.T) {
+	for name, manifest := range map[string]string{
+		"apollo":            `{"format":"apollo-persisted-query-manifest","version":1,"operations":[{"id":"abc","name":"A","type":"query","body":"query A { name }"}]}`,
+		"relay":             `{"abc":"query A { name }"}`,
+		"documentId list":   `[{"documentId":"sha256:abc","document":"query A { name }"}]`,
+		"documentId object": `{"documents":[{"documentId":"abc","document":"query A { name }"}]}`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			m, err := extension.LoadManifest(strings.NewReader(manifest))
+			require.NoError(t, err)
+			assert.Equal(t, 1, m.Len())
+
+			document, ok := m.Get("abc")
+			assert.True(t, ok)
+			assert.Equal(t, "query A { name }", document)
+
+			document, ok = m.Get("sha256:abc")
+			assert.True(t, ok)
+			assert.Equal(t, "query A { name }", document)
+
+			id, ok := m.Lookup("query A { name }")
+			assert.True(t, ok)
+			assert.Equal(t, "abc", id)
+
+			_, ok = m.Get("def")
+			assert.False(t, ok)
+		})
+	}
+
+	t.Run("errors", func(t *testing.T) {
+		for manifest, msg := range map[string]string{
+			`{`:                      "manifest could not be decoded: unexpected EOF",
+			`"abc"`:                  "manifest must be a JSON object or array",
+			`{"format":"other"}`:     `unsupported manifest format "other"`,
+			`[{"document":"{ a }"}]`: "manifest document without documentId",
+			`{"abc":1}`:              "relay manifest could not be decoded",
+		} {
+			_, err := extension.LoadManifest(strings.NewReader(manifest))
+			require.Error(t, err, manifest)
+			assert.True(t, strings.HasPrefix(err.Error(), msg), err.Error())
+		}
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		_, err := extension.LoadManifestFile("testdata/missing.json")
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,204 @@
+package extension
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/99designs/gqlgen/complexity"
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/99designs/gqlgen/graphql/errcode"
+	gqlextension "github.com/99designs/gqlgen/graphql/handler/extension"
+	fibergqlgen "github.com/NickTaporuk/fiber-gqlgen"
+	"github.com/gofiber/fiber/v2"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+const errRateLimitedCode = "RATE_LIMITED"
+
+func init() {
+	errcode.RegisterErrorType(errRateLimitedCode, errcode.KindProtocol)
+}
+
+// RateLimit charges every operation its complexity against a token bucket of the client that sent it,
+// which catches clients sending many cheap looking operations that each stay below the ComplexityLimit.
+//
+// Rejected operations get a RATE_LIMITED error, the HTTP transports answer them with 429 Too Many Requests
+// and a Retry-After header. Accepted operations report the budget left in the rateLimit response extension.
+type RateLimit struct {
+	// Key returns the key of the bucket an operation is charged to, eg an API key, the client IP from
+	// fibergqlgen.ForContext or a user id. Operations with an empty key are not limited.
+	Key func(ctx context.Context, rc *graphql.OperationContext) string
+
+	// Capacity is the size of every bucket, the cost a client can spend in a burst.
+	Capacity int
+
+	// Period is the time an empty bucket takes to fill up again, tokens are added continuously.
+	Period time.Duration
+
+	// Cost returns the cost of an operation, by default its complexity as calculated for ComplexityLimit.
+	Cost func(ctx context.Context, rc *graphql.OperationContext) int
+
+	// Store keeps the buckets, share one between replicas to enforce a global limit. By default every
+	// RateLimit keeps its own buckets in memory.
+	Store RateLimitStore
+
+	// OnError is called when the Store fails, the operation is let through. By default the error is
+	// written to the standard logger.
+	OnError func(ctx context.Context, err error)
+
+	es graphql.ExecutableSchema
+}
+
+// RateLimitStore is the interface a backend implements to keep the token buckets of RateLimit. Take must be
+// atomic, concurrent calls for the same key may come from several replicas.
+type RateLimitStore interface {
+	// Take removes cost tokens from the bucket of key when it holds enough of them, an empty or unknown
+	// bucket holds bucket.Capacity tokens.
+	Take(ctx context.Context, key string, cost int, bucket TokenBucket) (RateLimitResult, error)
+}
+
+// TokenBucket describes the buckets of a RateLimit
+type TokenBucket struct {
+	Capacity int
+	Period   time.Duration
+}
+
+// RateLimitResult is the state of a bucket after Take
+type RateLimitResult struct {
+	// Allowed is true if the tokens were taken
+	Allowed bool
+
+	// Remaining is the number of whole tokens left in the bucket
+	Remaining int
+
+	// RetryAfter is the time until the bucket holds enough tokens, zero when they were taken
+	RetryAfter time.Duration
+}
+
+type RateLimitStats struct {
+	// Key is the key of the bucket the operation was charged to
+	Key string
+
+	// Cost is the cost of the operation
+	Cost int
+
+	// Remaining is the budget left after the operation
+	Remaining int
+}
+
+const rateLimitExtension = "RateLimit"
+
+var _ interface {
+	graphql.OperationContextMutator
+	graphql.ResponseInterceptor
+	graphql.HandlerExtension
+} = &RateLimit{}
+
+func (r RateLimit) ExtensionName() string {
+	return rateLimitExtension
+}
+
+func (r *RateLimit) Validate(schema graphql.ExecutableSchema) error {
+	if r.Key == nil {
+		return fmt.Errorf("RateLimit key func can not be nil")
+	}
+	if r.Capacity <= 0 || r.Period <= 0 {
+		return fmt.Errorf("RateLimit capacity and period must be positive")
+	}
+	if r.Store == nil {
+		r.Store = NewRateLimitMemory()
+	}
+	r.es = schema
+	return nil
+}
+
+func (r RateLimit) MutateOperationContext(ctx context.Context, rc *graphql.OperationContext) *gqlerror.Error {
+	if rc.Operation == nil {
+		return nil
+	}
+
+	key := r.Key(ctx, rc)
+	if key == "" {
+		return nil
+	}
+
+	cost := r.cost(ctx, rc)
+	if cost > r.Capacity {
+		fibergqlgen.ResponseFromContext(ctx).SetStatus(fiber.StatusTooManyRequests)
+		return rateLimited(nil, "operation has cost %d, which exceeds the rate limit of %d", cost, r.Capacity)
+	}
+
+	res, err := r.Store.Take(ctx, key, cost, TokenBucket{Capacity: r.Capacity, Period: r.Period})
+	if err != nil {
+		r.error(ctx, err)
+		return nil
+	}
+
+	if !res.Allowed {
+		seconds := int(math.Ceil(res.RetryAfter.Seconds()))
+		resp := fibergqlgen.ResponseFromContext(ctx)
+		resp.SetStatus(fiber.StatusTooManyRequests)
+		resp.SetHeader(fiber.HeaderRetryAfter, strconv.Itoa(seconds))
+		return rateLimited(map[string]interface{}{"retryAfter": seconds}, "rate limit exceeded, retry in %ds", seconds)
+	}
+
+	rc.Stats.SetExtension(rateLimitExtension, &RateLimitStats{
+		Key:       key,
+		Cost:      cost,
+		Remaining: res.Remaining,
+	})
+
+	return nil
+}
+
+func (r RateLimit) InterceptResponse(ctx context.Context, next graphql.ResponseHandler) *graphql.Response {
+	if s := GetRateLimitStats(ctx); s != nil {
+		graphql.RegisterExtension(ctx, "rateLimit", map[string]interface{}{
+			"cost":      s.Cost,
+			"remaining": s.Remaining,
+			"capacity":  r.Capacity,
+		})
+	}
+
+	return next(ctx)
+}
+
+func GetRateLimitStats(ctx context.Context) *RateLimitStats {
+	rc := graphql.GetOperationContext(ctx)
+	if rc == nil {
+		return nil
+	}
+
+	s, _ := rc.Stats.GetExtension(rateLimitExtension).(*RateLimitStats)
+	return s
+}
+
+func (r RateLimit) cost(ctx context.Context, rc *graphql.OperationContext) int {
+	if r.Cost != nil {
+		return r.Cost(ctx, rc)
+	}
+	// reuse the complexity of a ComplexityLimit added before
+	if s, ok := rc.Stats.GetExtension("ComplexityLimit").(*gqlextension.ComplexityStats); ok {
+		return s.Complexity
+	}
+	return complexity.Calculate(r.es, rc.Operation, rc.Variables)
+}
+
+func (r RateLimit) error(ctx context.Context, err error) {
+	if r.OnError != nil {
+		r.OnError(ctx, err)
+		return
+	}
+	log.Printf("rate limit store: %s", err)
+}
+
+func rateLimited(extensions map[string]interface{}, format string, args ...interface{}) *gqlerror.Error {
+	err := gqlerror.Errorf(format, args...)
+	err.Extensions = extensions
+	errcode.Set(err, errRateLimitedCode)
+	return err
+}
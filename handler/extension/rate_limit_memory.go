@@ -0,0 +1,93 @@
+package extension
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// RateLimitMemory keeps the token buckets of a RateLimit in process memory, every replica enforces its own limit.
+type RateLimitMemory struct {
+	// Now returns the current time, it can be replaced to control refills in tests.
+	Now func() time.Time
+
+	mu      sync.Mutex
+	buckets map[string]*memoryBucket
+	swept   time.Time
+}
+
+type memoryBucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+var _ RateLimitStore = &RateLimitMemory{}
+
+// NewRateLimitMemory creates a RateLimitMemory without buckets
+func NewRateLimitMemory() *RateLimitMemory {
+	return &RateLimitMemory{Now: time.Now}
+}
+
+func (m *RateLimitMemory) Take(ctx context.Context, key string, cost int, bucket TokenBucket) (RateLimitResult, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.now()
+	m.sweep(now, bucket.Period)
+
+	if m.buckets == nil {
+		m.buckets = map[string]*memoryBucket{}
+	}
+	b, ok := m.buckets[key]
+	if !ok {
+		b = &memoryBucket{tokens: float64(bucket.Capacity), updated: now}
+		m.buckets[key] = b
+	}
+
+	rate := float64(bucket.Capacity) / float64(bucket.Period)
+	if elapsed := now.Sub(b.updated); elapsed > 0 {
+		b.tokens = math.Min(float64(bucket.Capacity), b.tokens+float64(elapsed)*rate)
+		b.updated = now
+	}
+
+	if b.tokens < float64(cost) {
+		return RateLimitResult{
+			Remaining:  int(b.tokens),
+			RetryAfter: time.Duration(math.Ceil((float64(cost) - b.tokens) / rate)),
+		}, nil
+	}
+
+	b.tokens -= float64(cost)
+	return RateLimitResult{Allowed: true, Remaining: int(b.tokens)}, nil
+}
+
+// Len returns the number of buckets, full ones included until they are swept
+func (m *RateLimitMemory) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return len(m.buckets)
+}
+
+// sweep drops the buckets untouched for a whole period once per period, they are full again and
+// would be recreated as such
+func (m *RateLimitMemory) sweep(now time.Time, period time.Duration) {
+	if now.Sub(m.swept) < period {
+		return
+	}
+	m.swept = now
+
+	for key, b := range m.buckets {
+		if now.Sub(b.updated) >= period {
+			delete(m.buckets, key)
+		}
+	}
+}
+
+func (m *RateLimitMemory) now() time.Time {
+	if m.Now == nil {
+		return time.Now()
+	}
+	return m.Now()
+}
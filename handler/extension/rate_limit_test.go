@@ -0,0 +1,168 @@
+package extension_test
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql"
+	fibergqlgen "github.com/NickTaporuk/fiber-gqlgen"
+	"github.com/NickTaporuk/fiber-gqlgen/handler/extension"
+	"github.com/NickTaporuk/fiber-gqlgen/handler/testserver"
+	"github.com/NickTaporuk/fiber-gqlgen/handler/transport"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type failingRateLimitStore struct{}
+
+func (failingRateLimitStore) Take(ctx context.Context, key string, cost int, bucket extension.TokenBucket) (extension.RateLimitResult, error) {
+	return extension.RateLimitResult{}, errors.New("unavailable")
+}
+
+func TestRateLimit(t *testing.T) {
+	apiKey := func(ctx context.Context, rc *graphql.OperationContext) string {
+		return fibergqlgen.ForContext(ctx).Header("X-Api-Key")
+	}
+
+	newApp := func(limit *extension.RateLimit, complexity int) *fiber.App {
+		h := testserver.New()
+		h.AddTransport(transport.POST{})
+		h.SetCalculatedComplexity(complexity)
+		h.Use(limit)
+
+		app := fiber.New()
+		app.Post("/graphql", h.ServeGraphQL)
+		return app
+	}
+
+	do := func(t *testing.T, app *fiber.App, key string) (*http.Response, string) {
+		req := httptest.NewRequest("POST", "/graphql", strings.NewReader(`{"query":"{ name }"}`))
+		req.Header.Set("Content-Type", "application/json")
+		if key != "" {
+			req.Header.Set("X-Api-Key", key)
+		}
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		b, err := ioutil.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return resp, string(b)
+	}
+
+	t.Run("operations are charged their complexity", func(t *testing.T) {
+		now := time.Unix(0, 0)
+		store := extension.NewRateLimitMemory()
+		store.Now = func() time.Time { return now }
+		app := newApp(&extension.RateLimit{Key: apiKey, Capacity: 10, Period: 10 * time.Second, Store: store}, 4)
+
+		resp, body := do(t, app, "a")
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, `{"data":{"name":"test"},"extensions":{"rateLimit":{"capacity":10,"cost":4,"remaining":6}}}`, body)
+
+		_, body = do(t, app, "a")
+		assert.Equal(t, `{"data":{"name":"test"},"extensions":{"rateLimit":{"capacity":10,"cost":4,"remaining":2}}}`, body)
+
+		resp, body = do(t, app, "a")
+		assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+		assert.Equal(t, "2", resp.Header.Get("Retry-After"))
+		assert.Equal(t, `{"errors":[{"message":"rate limit exceeded, retry in 2s","extensions":{"code":"RATE_LIMITED","retryAfter":2}}],"data":null}`, body)
+
+		t.Run("buckets are per key", func(t *testing.T) {
+			resp, _ := do(t, app, "b")
+			assert.Equal(t, http.StatusOK, resp.StatusCode)
+		})
+
+		t.Run("buckets refill over the period", func(t *testing.T) {
+			now = now.Add(2 * time.Second)
+			resp, body := do(t, app, "a")
+			assert.Equal(t, http.StatusOK, resp.StatusCode)
+			assert.Equal(t, `{"data":{"name":"test"},"extensions":{"rateLimit":{"capacity":10,"cost":4,"remaining":0}}}`, body)
+		})
+	})
+
+	t.Run("operations without a key are not limited", func(t *testing.T) {
+		app := newApp(&extension.RateLimit{Key: apiKey, Capacity: 1, Period: time.Minute}, 4)
+
+		resp, body := do(t, app, "")
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, `{"data":{"name":"test"}}`, body)
+	})
+
+	t.Run("operations costing more than the capacity", func(t *testing.T) {
+		app := newApp(&extension.RateLimit{Key: apiKey, Capacity: 3, Period: time.Minute}, 4)
+
+		resp, body := do(t, app, "a")
+		assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+		assert.Empty(t, resp.Header.Get("Retry-After"))
+		assert.Equal(t, `{"errors":[{"message":"operation has cost 4, which exceeds the rate limit of 3","extensions":{"code":"RATE_LIMITED"}}],"data":null}`, body)
+	})
+
+	t.Run("custom cost", func(t *testing.T) {
+		app := newApp(&extension.RateLimit{
+			Key:      apiKey,
+			Capacity: 3,
+			Period:   time.Minute,
+			Cost: func(ctx context.Context, rc *graphql.OperationContext) int {
+				return 1
+			},
+		}, 4)
+
+		_, body := do(t, app, "a")
+		assert.Equal(t, `{"data":{"name":"test"},"extensions":{"rateLimit":{"capacity":3,"cost":1,"remaining":2}}}`, body)
+	})
+
+	t.Run("store failures let operations through", func(t *testing.T) {
+		var storeErr error
+		app := newApp(&extension.RateLimit{
+			Key:      apiKey,
+			Capacity: 3,
+			Period:   time.Minute,
+			Store:    failingRateLimitStore{},
+			OnError: func(ctx context.Context, err error) {
+				storeErr = err
+			},
+		}, 1)
+
+		resp, body := do(t, app, "a")
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, `{"data":{"name":"test"}}`, body)
+		assert.EqualError(t, storeErr, "unavailable")
+	})
+}
+
+func TestRateLimitMemory(t *testing.T) {
+	now := time.Unix(0, 0)
+	store := extension.NewRateLimitMemory()
+	store.Now = func() time.Time { return now }
+	bucket := extension.TokenBucket{Capacity: 2, Period: time.Second}
+
+	res, err := store.Take(context.Background(), "a", 2, bucket)
+	require.NoError(t, err)
+	assert.Equal(t, extension.RateLimitResult{Allowed: true}, res)
+
+	res, err = store.Take(context.Background(), "a", 1, bucket)
+	require.NoError(t, err)
+	assert.Equal(t, extension.RateLimitResult{RetryAfter: 500 * time.Millisecond}, res)
+
+	now = now.Add(500 * time.Millisecond)
+	res, err = store.Take(context.Background(), "a", 1, bucket)
+	require.NoError(t, err)
+	assert.Equal(t, extension.RateLimitResult{Allowed: true}, res)
+
+	t.Run("idle buckets are swept", func(t *testing.T) {
+		_, err := store.Take(context.Background(), "b", 1, bucket)
+		require.NoError(t, err)
+		assert.Equal(t, 2, store.Len())
+
+		now = now.Add(2 * time.Second)
+		_, err = store.Take(context.Background(), "b", 1, bucket)
+		require.NoError(t, err)
+		assert.Equal(t, 1, store.Len())
+	})
+}
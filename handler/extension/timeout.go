@@ -0,0 +1,130 @@
+package extension
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/99designs/gqlgen/graphql/errcode"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+const errOperationTimeoutCode = "OPERATION_TIMEOUT"
+
+// OperationTimeout bounds how long an operation runs, with a separate limit per operation type. The
+// context of the resolvers gets the deadline, and the client receives an OPERATION_TIMEOUT error as
+// soon as it passes, even when a resolver ignores its context and keeps running.
+//
+// For subscriptions the limit applies to the whole subscription, which ends with the timeout error.
+type OperationTimeout struct {
+	// Query bounds query operations, zero means no limit.
+	Query time.Duration
+
+	// Mutation bounds mutation operations, zero means no limit. A mutation that times out may
+	// still complete in the background.
+	Mutation time.Duration
+
+	// Subscription bounds the lifetime of subscriptions, zero means no limit.
+	Subscription time.Duration
+}
+
+var _ interface {
+	graphql.OperationInterceptor
+	graphql.HandlerExtension
+} = OperationTimeout{}
+
+func (t OperationTimeout) ExtensionName() string {
+	return "OperationTimeout"
+}
+
+func (t OperationTimeout) Validate(schema graphql.ExecutableSchema) error {
+	if t.Query < 0 || t.Mutation < 0 || t.Subscription < 0 {
+		return fmt.Errorf("OperationTimeout durations can not be negative")
+	}
+	return nil
+}
+
+func (t OperationTimeout) InterceptOperation(ctx context.Context, next graphql.OperationHandler) graphql.ResponseHandler {
+	rc := graphql.GetOperationContext(ctx)
+	if rc.Operation == nil {
+		return next(ctx)
+	}
+
+	timeout := t.timeout(rc.Operation.Operation)
+	if timeout == 0 {
+		return next(ctx)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	responses := next(ctx)
+
+	done := false
+	return func(rctx context.Context) *graphql.Response {
+		if done {
+			return nil
+		}
+
+		type result struct {
+			resp  *graphql.Response
+			panic interface{}
+		}
+		ch := make(chan result, 1)
+		go func() {
+			defer func() {
+				if r := recover(); r != nil {
+					ch <- result{panic: r}
+				}
+			}()
+			ch <- result{resp: responses(rctx)}
+		}()
+
+		select {
+		case res := <-ch:
+			if res.panic != nil {
+				cancel()
+				// panics belong to the goroutine of the transport, where they are recovered
+				panic(res.panic)
+			}
+			if res.resp == nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				// the operation, typically a subscription, ended because of the deadline
+				done = true
+				cancel()
+				return timedOut(timeout)
+			}
+			if res.resp == nil {
+				done = true
+				cancel()
+			}
+			return res.resp
+		case <-ctx.Done():
+			done = true
+			cancel()
+			if !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				return nil
+			}
+			return timedOut(timeout)
+		}
+	}
+}
+
+func timedOut(timeout time.Duration) *graphql.Response {
+	err := gqlerror.Errorf("operation timed out after %s", timeout)
+	errcode.Set(err, errOperationTimeoutCode)
+	return &graphql.Response{Errors: gqlerror.List{err}}
+}
+
+func (t OperationTimeout) timeout(operation ast.Operation) time.Duration {
+	switch operation {
+	case ast.Query:
+		return t.Query
+	case ast.Mutation:
+		return t.Mutation
+	case ast.Subscription:
+		return t.Subscription
+	default:
+		return 0
+	}
+}
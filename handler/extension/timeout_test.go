@@ -0,0 +1,86 @@
+package extension_test
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/NickTaporuk/fiber-gqlgen/handler/extension"
+	"github.com/NickTaporuk/fiber-gqlgen/handler/testserver"
+	"github.com/NickTaporuk/fiber-gqlgen/handler/transport"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOperationTimeout(t *testing.T) {
+	newApp := func(timeout extension.OperationTimeout, fieldDelay time.Duration, deadline *bool) *fiber.App {
+		h := testserver.New()
+		h.AddTransport(transport.SSE{})
+		h.AddTransport(transport.POST{})
+		h.Use(timeout)
+		h.AroundFields(func(ctx context.Context, next graphql.Resolver) (interface{}, error) {
+			if deadline != nil {
+				_, *deadline = ctx.Deadline()
+			}
+			time.Sleep(fieldDelay)
+			return next(ctx)
+		})
+
+		app := fiber.New()
+		app.Post("/graphql", h.ServeGraphQL)
+		return app
+	}
+
+	do := func(t *testing.T, app *fiber.App, body string, accept string) string {
+		req := httptest.NewRequest("POST", "/graphql", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		if accept != "" {
+			req.Header.Set("Accept", accept)
+		}
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		b, err := ioutil.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return string(b)
+	}
+
+	t.Run("query within the timeout", func(t *testing.T) {
+		var deadline bool
+		app := newApp(extension.OperationTimeout{Query: time.Second}, 0, &deadline)
+
+		assert.Equal(t, `{"data":{"name":"test"}}`, do(t, app, `{"query":"{ name }"}`, ""))
+		assert.True(t, deadline, "resolvers get the deadline")
+	})
+
+	t.Run("query timing out", func(t *testing.T) {
+		app := newApp(extension.OperationTimeout{Query: 20 * time.Millisecond}, 500*time.Millisecond, nil)
+
+		start := time.Now()
+		assert.Equal(t, `{"errors":[{"message":"operation timed out after 20ms","extensions":{"code":"OPERATION_TIMEOUT"}}],"data":null}`,
+			do(t, app, `{"query":"{ name }"}`, ""))
+		assert.Less(t, time.Since(start), 400*time.Millisecond)
+	})
+
+	t.Run("limits are per operation type", func(t *testing.T) {
+		var deadline bool
+		app := newApp(extension.OperationTimeout{Mutation: 20 * time.Millisecond}, 50*time.Millisecond, &deadline)
+
+		assert.Equal(t, `{"data":{"name":"test"}}`, do(t, app, `{"query":"{ name }"}`, ""))
+		assert.False(t, deadline)
+	})
+
+	t.Run("subscription timing out", func(t *testing.T) {
+		app := newApp(extension.OperationTimeout{Subscription: 20 * time.Millisecond}, 0, nil)
+
+		body := do(t, app, `{"query":"subscription { name }"}`, "text/event-stream")
+		assert.Contains(t, body, "event: next\ndata: {\"errors\":[{\"message\":\"operation timed out after 20ms\",\"extensions\":{\"code\":\"OPERATION_TIMEOUT\"}}],\"data\":null}\n\n")
+		assert.True(t, strings.HasSuffix(body, "event: complete\ndata:\n\n"), body)
+	})
+}
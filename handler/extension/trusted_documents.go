@@ -0,0 +1,172 @@
+package extension
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/99designs/gqlgen/graphql/errcode"
+	"github.com/mitchellh/mapstructure"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+const (
+	errTrustedDocumentNotFound     = "PersistedQueryNotFound"
+	errTrustedDocumentNotFoundCode = "PERSISTED_QUERY_NOT_FOUND"
+	errTrustedDocumentRequired     = "only trusted documents can be executed"
+	errTrustedDocumentRequiredCode = "TRUSTED_DOCUMENT_REQUIRED"
+)
+
+// TrustedDocuments only lets operations run whose document is registered in the Manifest, also known
+// as a persisted operation safelist.
+//
+// Clients reference a registered document by its id, either with the documentId request parameter or
+// with the sha256Hash of the persistedQuery extension sent by Apollo clients. Free-form query strings
+// are accepted as long as the exact document is registered.
+//
+// In LogOnly mode nothing is rejected, unregistered operations are only reported to OnUnregistered.
+//
+// Extensions run in the order they are added, so add TrustedDocuments before AutomaticPersistedQuery,
+// which otherwise answers requests that only carry a hash before the manifest is consulted. The
+// AutomaticPersistedQuery of handler.NewDefaultServer and SetPersistedQueryCache looks up the manifest
+// itself, TrustedDocuments can be added after it.
+// see https://github.com/graphql/graphql-over-http/blob/main/rfcs/PersistedOperations.md
+type TrustedDocuments struct {
+	Manifest *Manifest
+
+	// LogOnly reports unregistered operations instead of rejecting them, which helps to find the
+	// clients that still send free-form queries before enforcing the safelist.
+	LogOnly bool
+
+	// OnUnregistered is called with the parameters of every unregistered operation, by default they
+	// are written to the standard logger.
+	OnUnregistered func(ctx context.Context, rawParams *graphql.RawParams)
+}
+
+type TrustedDocumentStats struct {
+	// DocumentID is the id of the registered document, empty for unregistered operations
+	DocumentID string
+
+	// Registered is true if the operation matched a document of the manifest
+	Registered bool
+}
+
+const trustedDocumentsExtension = "TrustedDocuments"
+
+var _ interface {
+	graphql.OperationParameterMutator
+	graphql.HandlerExtension
+} = TrustedDocuments{}
+
+func (t TrustedDocuments) ExtensionName() string {
+	return trustedDocumentsExtension
+}
+
+func (t TrustedDocuments) Validate(schema graphql.ExecutableSchema) error {
+	if t.Manifest == nil {
+		return fmt.Errorf("TrustedDocuments.Manifest can not be nil")
+	}
+	return nil
+}
+
+func (t TrustedDocuments) MutateOperationParameters(ctx context.Context, rawParams *graphql.RawParams) *gqlerror.Error {
+	id, err := documentID(rawParams)
+	if err != nil {
+		return err
+	}
+
+	var (
+		document   string
+		registered bool
+	)
+	if id != "" {
+		document, registered = t.Manifest.Get(id)
+		if registered && rawParams.Query != "" && rawParams.Query != document {
+			return gqlerror.Errorf("provided document does not match documentId")
+		}
+	} else if rawParams.Query != "" {
+		id, registered = t.Manifest.Lookup(rawParams.Query)
+	}
+
+	if !registered {
+		t.unregistered(ctx, rawParams)
+		if !t.LogOnly {
+			if id == "" {
+				err := gqlerror.Errorf(errTrustedDocumentRequired)
+				errcode.Set(err, errTrustedDocumentRequiredCode)
+				return err
+			}
+			err := gqlerror.Errorf(errTrustedDocumentNotFound)
+			errcode.Set(err, errTrustedDocumentNotFoundCode)
+			return err
+		}
+		id = ""
+	} else if rawParams.Query == "" {
+		rawParams.Query = document
+	}
+
+	graphql.GetOperationContext(ctx).Stats.SetExtension(trustedDocumentsExtension, &TrustedDocumentStats{
+		DocumentID: id,
+		Registered: registered,
+	})
+
+	return nil
+}
+
+func (t TrustedDocuments) unregistered(ctx context.Context, rawParams *graphql.RawParams) {
+	if t.OnUnregistered != nil {
+		t.OnUnregistered(ctx, rawParams)
+		return
+	}
+	log.Printf("unregistered graphql operation %q: %s", rawParams.OperationName, rawParams.Query)
+}
+
+func GetTrustedDocumentStats(ctx context.Context) *TrustedDocumentStats {
+	rc := graphql.GetOperationContext(ctx)
+	if rc == nil {
+		return nil
+	}
+
+	s, _ := rc.Stats.GetExtension(trustedDocumentsExtension).(*TrustedDocumentStats)
+	return s
+}
+
+// documentID reads the id of the requested document from the documentId parameter, which the transports
+// pass on as an extension, or from the persistedQuery extension
+func documentID(rawParams *graphql.RawParams) (string, *gqlerror.Error) {
+	if id, ok := rawParams.Extensions["documentId"]; ok {
+		s, ok := id.(string)
+		if !ok {
+			return "", gqlerror.Errorf("documentId must be a string")
+		}
+		return s, nil
+	}
+
+	if rawParams.Extensions["persistedQuery"] == nil {
+		return "", nil
+	}
+
+	var extension struct {
+		Sha256 string `mapstructure:"sha256Hash"`
+	}
+	if err := mapstructure.Decode(rawParams.Extensions["persistedQuery"], &extension); err != nil {
+		return "", gqlerror.Errorf("invalid APQ extension data")
+	}
+
+	return extension.Sha256, nil
+}
+
+// normalizeDocumentID drops the sha256: prefix used by the documentId format, so that ids in
+// either form match the plain hashes of the Apollo and Relay formats
+func normalizeDocumentID(id string) string {
+	return strings.TrimPrefix(id, "sha256:")
+}
+
+func computeDocumentHash(document string) string {
+	b := sha256.Sum256([]byte(document))
+	return hex.EncodeToString(b[:])
+}
@@ -0,0 +1,111 @@
+package extension_test
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/NickTaporuk/fiber-gqlgen/handler/extension"
+	"github.com/NickTaporuk/fiber-gqlgen/handler/testserver"
+	"github.com/NickTaporuk/fiber-gqlgen/handler/transport"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const trustedQuery = "query A { name }"
+
+func TestTrustedDocuments(t *testing.T) {
+	manifest := extension.NewManifest(map[string]string{"sha256:abc": trustedQuery})
+
+	newApp := func(td extension.TrustedDocuments) *fiber.App {
+		td.Manifest = manifest
+		h := testserver.New()
+		h.AddTransport(transport.GET{})
+		h.AddTransport(transport.POST{})
+		h.AddTransport(transport.MultipartForm{})
+		h.Use(td)
+
+		app := fiber.New()
+		app.All("/graphql", h.ServeGraphQL)
+		return app
+	}
+
+	do := func(t *testing.T, app *fiber.App, req *http.Request) string {
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		b, err := ioutil.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return string(b)
+	}
+
+	get := func(values url.Values) *http.Request {
+		return httptest.NewRequest("GET", "/graphql?"+values.Encode(), nil)
+	}
+	post := func(body string) *http.Request {
+		req := httptest.NewRequest("POST", "/graphql", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		return req
+	}
+	multipartForm := func(operations string) *http.Request {
+		var b bytes.Buffer
+		w := multipart.NewWriter(&b)
+		require.NoError(t, w.WriteField("operations", operations))
+		require.NoError(t, w.WriteField("map", `{}`))
+		require.NoError(t, w.Close())
+		req := httptest.NewRequest("POST", "/graphql", &b)
+		req.Header.Set("Content-Type", w.FormDataContentType())
+		return req
+	}
+
+	t.Run("enforcing", func(t *testing.T) {
+		app := newApp(extension.TrustedDocuments{OnUnregistered: func(ctx context.Context, rawParams *graphql.RawParams) {}})
+
+		for name, req := range map[string]*http.Request{
+			"GET documentId":             get(url.Values{"documentId": {"sha256:abc"}}),
+			"GET persistedQuery":         get(url.Values{"extensions": {`{"persistedQuery":{"version":1,"sha256Hash":"abc"}}`}}),
+			"GET registered query":       get(url.Values{"query": {trustedQuery}}),
+			"POST documentId":            post(`{"documentId":"sha256:abc"}`),
+			"POST persistedQuery":        post(`{"extensions":{"persistedQuery":{"version":1,"sha256Hash":"abc"}}}`),
+			"POST registered query":      post(`{"query":"query A { name }"}`),
+			"multipart documentId":       multipartForm(`{"documentId":"abc"}`),
+			"multipart registered query": multipartForm(`{"query":"query A { name }"}`),
+		} {
+			assert.Equal(t, `{"data":{"name":"test"}}`, do(t, app, req), name)
+		}
+
+		for name, req := range map[string]*http.Request{
+			"GET free-form":       get(url.Values{"query": {"{ name }"}}),
+			"POST free-form":      post(`{"query":"{ name }"}`),
+			"multipart free-form": multipartForm(`{"query":"{ name }"}`),
+		} {
+			assert.Equal(t, `{"errors":[{"message":"only trusted documents can be executed","extensions":{"code":"TRUSTED_DOCUMENT_REQUIRED"}}],"data":null}`, do(t, app, req), name)
+		}
+
+		assert.Equal(t, `{"errors":[{"message":"PersistedQueryNotFound","extensions":{"code":"PERSISTED_QUERY_NOT_FOUND"}}],"data":null}`,
+			do(t, app, post(`{"documentId":"sha256:def"}`)))
+		assert.Equal(t, `{"errors":[{"message":"provided document does not match documentId"}],"data":null}`,
+			do(t, app, post(`{"documentId":"sha256:abc","query":"{ name }"}`)))
+	})
+
+	t.Run("log only", func(t *testing.T) {
+		var reported []string
+		app := newApp(extension.TrustedDocuments{
+			LogOnly: true,
+			OnUnregistered: func(ctx context.Context, rawParams *graphql.RawParams) {
+				reported = append(reported, rawParams.Query)
+			},
+		})
+
+		assert.Equal(t, `{"data":{"name":"test"}}`, do(t, app, post(`{"query":"{ name }"}`)))
+		assert.Equal(t, `{"data":{"name":"test"}}`, do(t, app, post(`{"documentId":"sha256:abc"}`)))
+		assert.Equal(t, []string{"{ name }"}, reported)
+	})
+}
@@ -0,0 +1,136 @@
+package handler
+
+import (
+	"path"
+
+	fibergqlgen "github.com/NickTaporuk/fiber-gqlgen"
+	"github.com/NickTaporuk/fiber-gqlgen/playground"
+	"github.com/gofiber/fiber/v2"
+)
+
+// MountConfig defines the config for Server.Mount.
+type MountConfig struct {
+	// Playground defines the path of the playground page, relative to the mounted path.
+	//
+	// Optional. Default: "" (no playground)
+	Playground string
+
+	// PlaygroundConfig defines the config of the playground page, its Endpoint defaults to
+	// the full path the server is mounted on.
+	//
+	// Optional. Default: playground.ConfigDefault
+	PlaygroundConfig playground.Config
+
+	// Schema defines the path of the schema in SDL, relative to the mounted path.
+	//
+	// Optional. Default: "" (no schema endpoint)
+	Schema string
+
+	// Introspection defines the path of the schema as introspection JSON, relative to the mounted path.
+	//
+	// Optional. Default: "" (no introspection endpoint)
+	Introspection string
+
+	// SchemaConfig defines the config of the schema and introspection endpoints, its Format is set
+	// for each of them. The endpoints do not go through the IntrospectionPolicy of the server, restrict
+	// them with its Next when introspection is not public, skipped requests fall through to the next route.
+	//
+	// Optional. Default: SchemaConfigDefault
+	SchemaConfig SchemaConfig
+}
+
+// Handler returns the fiber.Handler serving GraphQL requests with the configured transports
+func (s *Server) Handler() fiber.Handler {
+	return s.ServeGraphQL
+}
+
+// Mount registers the server on router at path for the methods of the configured transports.
+// Transports that do not implement fibergqlgen.TransportMethods are served on every method, HEAD is
+// served like GET.
+//
+// The playground, the schema and its introspection are mounted below path when enabled in the config, router can
+// be a fiber.App or a group of one.
+func (s *Server) Mount(router fiber.Router, path string, config ...MountConfig) {
+	var cfg MountConfig
+	if len(config) > 0 {
+		cfg = config[0]
+	}
+
+	methods, all := s.methods()
+	if all {
+		router.All(path, s.ServeGraphQL)
+	} else {
+		served := map[string]bool{}
+		for _, method := range methods {
+			router.Add(method, path, s.ServeGraphQL)
+			served[method] = true
+		}
+		// HEAD is answered like GET, unless a transport such as Options serves it
+		if served[fiber.MethodGet] && !served[fiber.MethodHead] {
+			router.Head(path, s.serveHead)
+		}
+	}
+
+	if cfg.Playground != "" {
+		playgroundConfig := cfg.PlaygroundConfig
+		if playgroundConfig.Endpoint == "" {
+			playgroundConfig.Endpoint = joinPath(routerPrefix(router), path)
+		}
+		page := playground.New(playgroundConfig)
+		router.Get(joinPath(path, cfg.Playground), page)
+		if playgroundConfig.Assets == playground.AssetsEmbedded {
+			router.Get(joinPath(path, cfg.Playground, "assets", "*"), page)
+		}
+	}
+
+	if cfg.Schema != "" {
+		schemaConfig := cfg.SchemaConfig
+		schemaConfig.Format = SchemaFormatSDL
+		router.Get(joinPath(path, cfg.Schema), s.SchemaHandler(schemaConfig))
+	}
+
+	if cfg.Introspection != "" {
+		schemaConfig := cfg.SchemaConfig
+		schemaConfig.Format = SchemaFormatIntrospection
+		router.Get(joinPath(path, cfg.Introspection), s.SchemaHandler(schemaConfig))
+	}
+}
+
+// methods returns the HTTP methods the transports are served on, all is true when a transport
+// does not tell its methods
+func (s *Server) methods() (methods []string, all bool) {
+	seen := map[string]bool{}
+	for _, t := range s.transports {
+		tm, ok := t.(fibergqlgen.TransportMethods)
+		if !ok {
+			return nil, true
+		}
+		for _, method := range tm.Methods() {
+			if !seen[method] {
+				seen[method] = true
+				methods = append(methods, method)
+			}
+		}
+	}
+	return methods, false
+}
+
+// serveHead answers a HEAD request like the same GET request, fasthttp drops the body and keeps the
+// headers
+func (s *Server) serveHead(c *fiber.Ctx) error {
+	c.Method(fiber.MethodGet)
+	defer c.Method(fiber.MethodHead)
+
+	return s.ServeGraphQL(c)
+}
+
+func routerPrefix(router fiber.Router) string {
+	if grp, ok := router.(*fiber.Group); ok {
+		return grp.Prefix
+	}
+	return ""
+}
+
+func joinPath(elem ...string) string {
+	return path.Join(append([]string{"/"}, elem...)...)
+}
@@ -0,0 +1,147 @@
+package handler_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/NickTaporuk/fiber-gqlgen/handler"
+	"github.com/NickTaporuk/fiber-gqlgen/handler/testserver"
+	"github.com/NickTaporuk/fiber-gqlgen/handler/transport"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type anyTransport struct{}
+
+func (anyTransport) Supports(c *fiber.Ctx) bool {
+	return true
+}
+
+func (anyTransport) Do(c *fiber.Ctx, exec graphql.GraphExecutor) error {
+	return c.SendStatus(http.StatusNoContent)
+}
+
+func routes(app *fiber.App) []string {
+	var routes []string
+	for _, stack := range app.Stack() {
+		for _, route := range stack {
+			routes = append(routes, route.Method+" "+route.Path)
+		}
+	}
+	sort.Strings(routes)
+	return routes
+}
+
+func TestMount(t *testing.T) {
+	t.Run("registers the methods of the transports", func(t *testing.T) {
+		srv := testserver.New()
+		srv.AddTransport(transport.Options{})
+		srv.AddTransport(transport.GET{})
+		srv.AddTransport(transport.POST{})
+		srv.AddTransport(transport.MultipartForm{})
+
+		app := fiber.New()
+		srv.Mount(app, "/query")
+
+		assert.Equal(t, []string{"GET /query", "HEAD /query", "OPTIONS /query", "POST /query"}, routes(app))
+	})
+
+	t.Run("HEAD is served like GET without Options", func(t *testing.T) {
+		srv := testserver.New()
+		srv.AddTransport(transport.GET{})
+		srv.AddTransport(transport.POST{})
+
+		app := fiber.New()
+		srv.Mount(app, "/query")
+		assert.Equal(t, []string{"GET /query", "HEAD /query", "POST /query"}, routes(app))
+
+		resp, err := app.Test(httptest.NewRequest("HEAD", "/query?query={name}", nil))
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+		assert.Equal(t, "24", resp.Header.Get("Content-Length"))
+		b, err := ioutil.ReadAll(resp.Body)
+		require.NoError(t, err)
+		assert.Empty(t, b)
+	})
+
+	t.Run("transports without methods are served on every method", func(t *testing.T) {
+		srv := testserver.New()
+		srv.AddTransport(transport.GET{})
+		srv.AddTransport(anyTransport{})
+
+		app := fiber.New()
+		srv.Mount(app, "/query")
+
+		assert.Len(t, routes(app), len(app.Stack()))
+	})
+
+	t.Run("groups with playground and schema", func(t *testing.T) {
+		srv := testserver.New()
+		srv.AddTransport(transport.POST{})
+
+		app := fiber.New()
+		srv.Mount(app.Group("/api"), "/query", handler.MountConfig{
+			Playground:    "/playground",
+			Schema:        "/schema.graphql",
+			Introspection: "/schema.json",
+		})
+
+		assert.Equal(t, []string{
+			"GET /api/query/playground",
+			"GET /api/query/schema.graphql",
+			"GET /api/query/schema.json",
+			"HEAD /api/query/playground",
+			"HEAD /api/query/schema.graphql",
+			"HEAD /api/query/schema.json",
+			"POST /api/query",
+		}, routes(app))
+
+		req := httptest.NewRequest("POST", "/api/query", strings.NewReader(`{"query":"{ name }"}`))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		b, err := ioutil.ReadAll(resp.Body)
+		require.NoError(t, err)
+		assert.Equal(t, `{"data":{"name":"test"}}`, string(b))
+
+		resp, err = app.Test(httptest.NewRequest("GET", "/api/query/playground", nil))
+		require.NoError(t, err)
+		b, err = ioutil.ReadAll(resp.Body)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Contains(t, string(b), `location.host + '\/api\/query'`)
+
+		resp, err = app.Test(httptest.NewRequest("GET", "/api/query/schema.graphql", nil))
+		require.NoError(t, err)
+		b, err = ioutil.ReadAll(resp.Body)
+		require.NoError(t, err)
+		assert.Equal(t, "text/plain; charset=utf-8", resp.Header.Get("Content-Type"))
+		assert.Contains(t, string(b), "type Query {\n\tname: String!\n")
+
+		resp, err = app.Test(httptest.NewRequest("GET", "/api/query/schema.json", nil))
+		require.NoError(t, err)
+		b, err = ioutil.ReadAll(resp.Body)
+		require.NoError(t, err)
+		assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+		assert.Equal(t, `{"data":{"name":"test"}}`, string(b), "the test server answers the introspection query like any other")
+	})
+
+	t.Run("handler", func(t *testing.T) {
+		srv := testserver.New()
+		srv.AddTransport(transport.GET{})
+
+		app := fiber.New()
+		app.Get("/query", srv.Handler())
+
+		resp, err := app.Test(httptest.NewRequest("GET", "/query?query={name}", nil))
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	})
+}
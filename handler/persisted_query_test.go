@@ -0,0 +1,105 @@
+package handler_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/NickTaporuk/fiber-gqlgen/handler"
+	"github.com/NickTaporuk/fiber-gqlgen/handler/extension"
+	"github.com/NickTaporuk/fiber-gqlgen/handler/store"
+	"github.com/NickTaporuk/fiber-gqlgen/handler/testserver"
+	"github.com/NickTaporuk/fiber-gqlgen/handler/transport"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vektah/gqlparser/v2"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+func TestSetPersistedQueryCache(t *testing.T) {
+	query := "{ name }"
+	sum := sha256.Sum256([]byte(query))
+	hash := hex.EncodeToString(sum[:])
+
+	kv := store.NewMemory()
+	h := testserver.New()
+	h.AddTransport(transport.POST{})
+	h.SetPersistedQueryCache(store.New(kv, store.Config{Prefix: "apq:"}))
+
+	app := fiber.New()
+	app.Post("/graphql", h.ServeGraphQL)
+
+	do := func(t *testing.T, body string) string {
+		req := httptest.NewRequest("POST", "/graphql", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		b, err := ioutil.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return string(b)
+	}
+
+	extensions := fmt.Sprintf(`"extensions":{"persistedQuery":{"version":1,"sha256Hash":%q}}`, hash)
+	assert.Contains(t, do(t, `{`+extensions+`}`), "PersistedQueryNotFound")
+	assert.Equal(t, `{"data":{"name":"test"}}`, do(t, `{"query":"{ name }",`+extensions+`}`))
+
+	value, ok, err := kv.Get(context.Background(), "apq:"+hash)
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, query, string(value))
+
+	assert.Equal(t, `{"data":{"name":"test"}}`, do(t, `{`+extensions+`}`))
+}
+
+func TestTrustedDocumentsAfterPersistedQueries(t *testing.T) {
+	query := "query A { name }"
+	sum := sha256.Sum256([]byte(query))
+	hash := hex.EncodeToString(sum[:])
+
+	h := handler.NewDefaultServer(&graphql.ExecutableSchemaMock{
+		ExecFunc: func(ctx context.Context) graphql.ResponseHandler {
+			return graphql.OneShot(&graphql.Response{Data: []byte(`{"name":"test"}`)})
+		},
+		SchemaFunc: func() *ast.Schema {
+			return gqlparser.MustLoadSchema(&ast.Source{Input: `
+				type Query {
+					name: String!
+				}
+			`})
+		},
+	})
+	h.Use(extension.TrustedDocuments{
+		Manifest:       extension.NewManifest(map[string]string{hash: query}),
+		OnUnregistered: func(ctx context.Context, rawParams *graphql.RawParams) {},
+	})
+
+	app := fiber.New()
+	app.Post("/graphql", h.ServeGraphQL)
+
+	do := func(t *testing.T, body string) string {
+		req := httptest.NewRequest("POST", "/graphql", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		b, err := ioutil.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return string(b)
+	}
+
+	extensions := func(hash string) string {
+		return fmt.Sprintf(`"extensions":{"persistedQuery":{"version":1,"sha256Hash":%q}}`, hash)
+	}
+	assert.Equal(t, `{"data":{"name":"test"}}`, do(t, `{`+extensions(hash)+`}`))
+
+	sum = sha256.Sum256([]byte("{ name }"))
+	unregistered := hex.EncodeToString(sum[:])
+	assert.Contains(t, do(t, `{"query":"{ name }",`+extensions(unregistered)+`}`), "PersistedQueryNotFound")
+	assert.Contains(t, do(t, `{`+extensions(unregistered)+`}`), "PersistedQueryNotFound", "the cached query is not trusted")
+}
@@ -0,0 +1,133 @@
+package handler_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/NickTaporuk/fiber-gqlgen/handler/testserver"
+	"github.com/NickTaporuk/fiber-gqlgen/handler/transport"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+func TestServeGraphQLRecover(t *testing.T) {
+	type panicked struct {
+		value interface{}
+		stack string
+	}
+
+	newApp := func(configure func(srv *testserver.TestServer)) (*fiber.App, *[]panicked) {
+		var panics []panicked
+		srv := testserver.New()
+		srv.AddTransport(transport.GET{})
+		srv.AddTransport(transport.POST{})
+		srv.AddTransport(transport.GRAPHQL{})
+		srv.AddTransport(transport.URLEncodedForm{DisableCSRFPrevention: true})
+		srv.AddTransport(transport.MultipartForm{})
+		srv.SetRecoverFunc(func(ctx context.Context, err interface{}) error {
+			return fmt.Errorf("recovered: %v", err)
+		})
+		srv.SetPanicHook(func(c *fiber.Ctx, err interface{}, stack []byte) {
+			panics = append(panics, panicked{value: err, stack: string(stack)})
+		})
+		srv.AroundResponses(func(ctx context.Context, next graphql.ResponseHandler) *graphql.Response {
+			panic("boom")
+		})
+		if configure != nil {
+			configure(srv)
+		}
+
+		app := fiber.New()
+		app.Use(func(c *fiber.Ctx) error {
+			c.Set("Access-Control-Allow-Origin", "*")
+			return c.Next()
+		})
+		app.All("/graphql", srv.ServeGraphQL)
+		return app, &panics
+	}
+
+	multipartRequest := func() *http.Request {
+		var b bytes.Buffer
+		w := multipart.NewWriter(&b)
+		require.NoError(t, w.WriteField("operations", `{"query":"{ name }"}`))
+		require.NoError(t, w.WriteField("map", `{}`))
+		require.NoError(t, w.Close())
+		req := httptest.NewRequest("POST", "/graphql", &b)
+		req.Header.Set("Content-Type", w.FormDataContentType())
+		return req
+	}
+	request := func(method, contentType, body string) *http.Request {
+		req := httptest.NewRequest(method, "/graphql", strings.NewReader(body))
+		req.Header.Set("Content-Type", contentType)
+		return req
+	}
+
+	for name, newRequest := range map[string]func() *http.Request{
+		"GET": func() *http.Request {
+			return httptest.NewRequest("GET", "/graphql?query="+url.QueryEscape("{ name }"), nil)
+		},
+		"POST": func() *http.Request {
+			return request("POST", "application/json", `{"query":"{ name }"}`)
+		},
+		"GRAPHQL": func() *http.Request {
+			return request("POST", "application/graphql", `{ name }`)
+		},
+		"URLEncodedForm": func() *http.Request {
+			return request("POST", "application/x-www-form-urlencoded", "query="+url.QueryEscape("{ name }"))
+		},
+		"MultipartForm": multipartRequest,
+	} {
+		t.Run(name, func(t *testing.T) {
+			app, panics := newApp(nil)
+
+			resp, err := app.Test(newRequest())
+			require.NoError(t, err)
+			b, err := ioutil.ReadAll(resp.Body)
+			require.NoError(t, err)
+
+			assert.Equal(t, http.StatusUnprocessableEntity, resp.StatusCode)
+			assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+			assert.Equal(t, "*", resp.Header.Get("Access-Control-Allow-Origin"))
+			assert.Equal(t, `{"errors":[{"message":"recovered: boom"}],"data":null}`, string(b))
+
+			require.Len(t, *panics, 1)
+			assert.Equal(t, "boom", (*panics)[0].value)
+			assert.Contains(t, (*panics)[0].stack, "panic")
+		})
+	}
+
+	t.Run("configured status", func(t *testing.T) {
+		app, _ := newApp(func(srv *testserver.TestServer) {
+			srv.SetRecoveredStatus(http.StatusInternalServerError)
+		})
+
+		resp, err := app.Test(request("POST", "application/json", `{"query":"{ name }"}`))
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+	})
+
+	t.Run("presented error", func(t *testing.T) {
+		app, _ := newApp(func(srv *testserver.TestServer) {
+			srv.SetErrorPresenter(func(ctx context.Context, err error) *gqlerror.Error {
+				return &gqlerror.Error{Message: "presented", Extensions: map[string]interface{}{"code": "INTERNAL"}}
+			})
+		})
+
+		resp, err := app.Test(request("POST", "application/json", `{"query":"{ name }"}`))
+		require.NoError(t, err)
+		b, err := ioutil.ReadAll(resp.Body)
+		require.NoError(t, err)
+		assert.Equal(t, `{"errors":[{"message":"presented","extensions":{"code":"INTERNAL"}}],"data":null}`, string(b))
+	})
+}
@@ -0,0 +1,87 @@
+package handler_test
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/99designs/gqlgen/graphql"
+	fibergqlgen "github.com/NickTaporuk/fiber-gqlgen"
+	"github.com/NickTaporuk/fiber-gqlgen/handler/testserver"
+	"github.com/NickTaporuk/fiber-gqlgen/handler/transport"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServeGraphQLResponse(t *testing.T) {
+	srv := testserver.New()
+	srv.AddTransport(transport.SSE{})
+	srv.AddTransport(transport.GET{})
+	srv.AddTransport(transport.POST{EnableBatching: true})
+	srv.AddTransport(transport.MultipartForm{})
+	srv.AroundFields(func(ctx context.Context, next graphql.Resolver) (interface{}, error) {
+		r := fibergqlgen.ResponseFromContext(ctx)
+		r.SetHeader("Cache-Control", "max-age=60")
+		r.SetCookie(&fiber.Cookie{Name: "session", Value: "abc", HTTPOnly: true})
+		r.SetStatus(http.StatusCreated)
+		return next(ctx)
+	})
+
+	app := fiber.New()
+	app.All("/graphql", srv.ServeGraphQL)
+
+	multipartRequest := func() *http.Request {
+		var b bytes.Buffer
+		w := multipart.NewWriter(&b)
+		require.NoError(t, w.WriteField("operations", `{"query":"{ name }"}`))
+		require.NoError(t, w.WriteField("map", `{}`))
+		require.NoError(t, w.Close())
+		req := httptest.NewRequest("POST", "/graphql", &b)
+		req.Header.Set("Content-Type", w.FormDataContentType())
+		return req
+	}
+	postRequest := func(body string) *http.Request {
+		req := httptest.NewRequest("POST", "/graphql", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		return req
+	}
+	multipartMixedRequest := func() *http.Request {
+		req := postRequest(`{"query":"{ name }"}`)
+		req.Header.Set("Accept", "multipart/mixed")
+		return req
+	}
+	sseRequest := func() *http.Request {
+		req := postRequest(`{"query":"{ name }"}`)
+		req.Header.Set("Accept", "text/event-stream")
+		return req
+	}
+
+	for name, req := range map[string]*http.Request{
+		"GET":             httptest.NewRequest("GET", "/graphql?query="+url.QueryEscape("{ name }"), nil),
+		"POST":            postRequest(`{"query":"{ name }"}`),
+		"POST batch":      postRequest(`[{"query":"{ name }"}]`),
+		"MultipartForm":   multipartRequest(),
+		"multipart/mixed": multipartMixedRequest(),
+		"SSE":             sseRequest(),
+	} {
+		t.Run(name, func(t *testing.T) {
+			resp, err := app.Test(req)
+			require.NoError(t, err)
+			b, err := ioutil.ReadAll(resp.Body)
+			require.NoError(t, err)
+
+			assert.Equal(t, http.StatusCreated, resp.StatusCode, string(b))
+			assert.Equal(t, "max-age=60", resp.Header.Get("Cache-Control"))
+			require.Len(t, resp.Cookies(), 1)
+			assert.Equal(t, "abc", resp.Cookies()[0].Value)
+			assert.True(t, resp.Cookies()[0].HttpOnly)
+		})
+	}
+}
@@ -0,0 +1,278 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/99designs/gqlgen/graphql/executor"
+	"github.com/99designs/gqlgen/graphql/handler/extension"
+	"github.com/99designs/gqlgen/graphql/introspection"
+	"github.com/gofiber/fiber/v2"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/formatter"
+)
+
+// The representations of the schema served by Server.SchemaHandler
+const (
+	SchemaFormatSDL           = "sdl"
+	SchemaFormatIntrospection = "introspection"
+)
+
+// SchemaConfig defines the config for Server.SchemaHandler.
+type SchemaConfig struct {
+	// Format defines the representation of the schema, SchemaFormatSDL serves it as text/plain and
+	// SchemaFormatIntrospection as the JSON result of the introspection query.
+	//
+	// Optional. Default: SchemaFormatSDL
+	Format string
+
+	// InternalDirectives defines the directives removed from the schema, their definitions as well as
+	// the places they are used, eg directives only meant for the server like gqlgen's goField.
+	//
+	// Optional. Default: nil
+	InternalDirectives []string
+
+	// CacheControl defines the Cache-Control header, clients revalidate their copy with the ETag
+	// derived from the schema.
+	//
+	// Optional. Default: "no-cache"
+	CacheControl string
+
+	// Next defines a function to skip this handler when returned true. The schema is served to every
+	// request otherwise, whatever the IntrospectionPolicy of the server, so restrict it the same way
+	// when introspection is not public.
+	//
+	// Optional. Default: nil
+	Next func(c *fiber.Ctx) bool
+}
+
+// SchemaConfigDefault is the default config
+var SchemaConfigDefault = SchemaConfig{
+	Format:       SchemaFormatSDL,
+	CacheControl: "no-cache",
+}
+
+func schemaConfigDefault(config ...SchemaConfig) SchemaConfig {
+	// Return default config if nothing provided
+	if len(config) < 1 {
+		return SchemaConfigDefault
+	}
+
+	// Override default config
+	cfg := config[0]
+
+	// Set default values
+	if cfg.Format == "" {
+		cfg.Format = SchemaConfigDefault.Format
+	}
+
+	if cfg.CacheControl == "" {
+		cfg.CacheControl = SchemaConfigDefault.CacheControl
+	}
+
+	return cfg
+}
+
+// SchemaHandler returns a fiber.Handler serving the schema of the server, eg for the codegen of clients
+// that should not depend on introspection being enabled. The response is rendered once, requests with
+// a matching If-None-Match header are answered with 304 Not Modified. The introspection JSON is the
+// result of the introspection query run by the executor, without the extensions of the server.
+func (s *Server) SchemaHandler(config ...SchemaConfig) fiber.Handler {
+	cfg := schemaConfigDefault(config...)
+
+	var (
+		body        []byte
+		contentType string
+	)
+	switch cfg.Format {
+	case SchemaFormatSDL:
+		var b bytes.Buffer
+		formatter.NewFormatter(&b).FormatSchema(stripDirectives(s.schema.Schema(), cfg.InternalDirectives))
+		body, contentType = b.Bytes(), "text/plain; charset=utf-8"
+	case SchemaFormatIntrospection:
+		body, contentType = introspect(s.schema, cfg.InternalDirectives), fiber.MIMEApplicationJSON
+	default:
+		panic("handler: unknown schema format " + cfg.Format)
+	}
+
+	sum := sha256.Sum256(body)
+	etag := `"` + hex.EncodeToString(sum[:]) + `"`
+
+	return func(c *fiber.Ctx) error {
+		// Don't execute middleware if Next returns true
+		if cfg.Next != nil && cfg.Next(c) {
+			return c.Next()
+		}
+
+		c.Set(fiber.HeaderETag, etag)
+		c.Set(fiber.HeaderCacheControl, cfg.CacheControl)
+		if etagMatches(c.Get(fiber.HeaderIfNoneMatch), etag) {
+			return c.SendStatus(fiber.StatusNotModified)
+		}
+
+		c.Set(fiber.HeaderContentType, contentType)
+		return c.Send(body)
+	}
+}
+
+// etagMatches reports whether the If-None-Match header lists etag, weak comparison as in RFC 9110
+func etagMatches(header, etag string) bool {
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" || strings.TrimPrefix(tag, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// stripDirectives returns a copy of schema without the directives named in names
+func stripDirectives(schema *ast.Schema, names []string) *ast.Schema {
+	if len(names) == 0 {
+		return schema
+	}
+
+	internal := map[string]bool{}
+	for _, name := range names {
+		internal[name] = true
+	}
+	filter := func(list ast.DirectiveList) ast.DirectiveList {
+		var kept ast.DirectiveList
+		for _, d := range list {
+			if !internal[d.Name] {
+				kept = append(kept, d)
+			}
+		}
+		return kept
+	}
+	arguments := func(list ast.ArgumentDefinitionList) ast.ArgumentDefinitionList {
+		var kept ast.ArgumentDefinitionList
+		for _, arg := range list {
+			copied := *arg
+			copied.Directives = filter(arg.Directives)
+			kept = append(kept, &copied)
+		}
+		return kept
+	}
+
+	stripped := *schema
+	stripped.Directives = map[string]*ast.DirectiveDefinition{}
+	for name, d := range schema.Directives {
+		if !internal[name] {
+			copied := *d
+			copied.Arguments = arguments(d.Arguments)
+			stripped.Directives[name] = &copied
+		}
+	}
+
+	stripped.Types = map[string]*ast.Definition{}
+	for name, def := range schema.Types {
+		copied := *def
+		copied.Directives = filter(def.Directives)
+		copied.Fields = nil
+		for _, field := range def.Fields {
+			f := *field
+			f.Directives = filter(field.Directives)
+			f.Arguments = arguments(field.Arguments)
+			copied.Fields = append(copied.Fields, &f)
+		}
+		copied.EnumValues = nil
+		for _, value := range def.EnumValues {
+			v := *value
+			v.Directives = filter(value.Directives)
+			copied.EnumValues = append(copied.EnumValues, &v)
+		}
+		stripped.Types[name] = &copied
+	}
+	stripped.Query = stripped.Types[nameOf(schema.Query)]
+	stripped.Mutation = stripped.Types[nameOf(schema.Mutation)]
+	stripped.Subscription = stripped.Types[nameOf(schema.Subscription)]
+
+	return &stripped
+}
+
+func nameOf(def *ast.Definition) string {
+	if def == nil {
+		return ""
+	}
+	return def.Name
+}
+
+// introspect answers the introspection query with the executor, without the extensions of the server,
+// so the JSON is the same as the __schema of a GraphQL request. The definitions of internal directives
+// are removed, standard introspection does not tell where directives are used.
+func introspect(es graphql.ExecutableSchema, internal []string) []byte {
+	exec := executor.New(es)
+	exec.Use(extension.Introspection{})
+
+	ctx := graphql.StartOperationTrace(context.Background())
+	rc, errs := exec.CreateOperationContext(ctx, &graphql.RawParams{Query: introspection.Query})
+	if errs != nil {
+		panic(fmt.Errorf("handler: introspecting the schema failed: %w", errs))
+	}
+	responses, ctx := exec.DispatchOperation(ctx, rc)
+	resp := responses(ctx)
+	if resp.Errors != nil {
+		panic(fmt.Errorf("handler: introspecting the schema failed: %w", resp.Errors))
+	}
+
+	if len(internal) > 0 {
+		resp.Data = stripDirectiveDefinitions(resp.Data, internal)
+	}
+
+	b, err := json.Marshal(resp)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+// stripDirectiveDefinitions removes the directives named in names from the result of the introspection query
+func stripDirectiveDefinitions(data json.RawMessage, names []string) json.RawMessage {
+	internal := map[string]bool{}
+	for _, name := range names {
+		internal[name] = true
+	}
+
+	var result struct {
+		Schema map[string]json.RawMessage `json:"__schema"`
+	}
+	if err := json.Unmarshal(data, &result); err != nil {
+		panic(err)
+	}
+	var directives []json.RawMessage
+	if err := json.Unmarshal(result.Schema["directives"], &directives); err != nil {
+		panic(err)
+	}
+
+	kept := []json.RawMessage{}
+	for _, raw := range directives {
+		var d struct {
+			Name string `json:"name"`
+		}
+		if err := json.Unmarshal(raw, &d); err != nil {
+			panic(err)
+		}
+		if !internal[d.Name] {
+			kept = append(kept, raw)
+		}
+	}
+
+	b, err := json.Marshal(kept)
+	if err != nil {
+		panic(err)
+	}
+	result.Schema["directives"] = b
+
+	b, err = json.Marshal(result)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
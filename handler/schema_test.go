@@ -0,0 +1,122 @@
+package handler_test
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/NickTaporuk/fiber-gqlgen/handler"
+	"github.com/NickTaporuk/fiber-gqlgen/handler/extension"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vektah/gqlparser/v2"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+func TestSchemaHandler(t *testing.T) {
+	schema := gqlparser.MustLoadSchema(&ast.Source{Input: `
+		directive @goField(forceResolver: Boolean) on FIELD_DEFINITION
+		directive @auth(role: String!) on FIELD_DEFINITION
+
+		type Query {
+			name: String! @goField(forceResolver: true)
+			secret: String @auth(role: "admin")
+			old: String @deprecated(reason: "use name")
+		}
+	`})
+	srv := handler.New(&graphql.ExecutableSchemaMock{
+		SchemaFunc: func() *ast.Schema {
+			return schema
+		},
+	})
+
+	do := func(t *testing.T, h fiber.Handler, headers map[string]string) (*http.Response, string) {
+		app := fiber.New()
+		app.Get("/schema", h)
+
+		req := httptest.NewRequest("GET", "/schema", nil)
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		b, err := ioutil.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return resp, string(b)
+	}
+
+	t.Run("sdl", func(t *testing.T) {
+		resp, body := do(t, srv.SchemaHandler(), nil)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "text/plain; charset=utf-8", resp.Header.Get("Content-Type"))
+		assert.Equal(t, "no-cache", resp.Header.Get("Cache-Control"))
+		assert.Regexp(t, `^"[0-9a-f]{64}"$`, resp.Header.Get("ETag"))
+		assert.Contains(t, body, "directive @goField(forceResolver: Boolean) on FIELD_DEFINITION")
+		assert.Contains(t, body, "name: String! @goField(forceResolver: true)")
+	})
+
+	t.Run("revalidation", func(t *testing.T) {
+		h := srv.SchemaHandler(handler.SchemaConfig{CacheControl: "public, max-age=60"})
+		resp, _ := do(t, h, nil)
+		etag := resp.Header.Get("ETag")
+		assert.Equal(t, "public, max-age=60", resp.Header.Get("Cache-Control"))
+
+		resp, body := do(t, h, map[string]string{"If-None-Match": `"other", W/` + etag})
+		assert.Equal(t, http.StatusNotModified, resp.StatusCode)
+		assert.Equal(t, etag, resp.Header.Get("ETag"))
+		assert.Empty(t, body)
+
+		resp, _ = do(t, h, map[string]string{"If-None-Match": `"other"`})
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	})
+
+	t.Run("internal directives", func(t *testing.T) {
+		resp, body := do(t, srv.SchemaHandler(handler.SchemaConfig{InternalDirectives: []string{"goField"}}), nil)
+		assert.NotContains(t, body, "goField")
+		assert.Contains(t, body, "name: String!\n")
+		assert.Contains(t, body, `secret: String @auth(role: "admin")`)
+
+		full, _ := do(t, srv.SchemaHandler(), nil)
+		assert.NotEqual(t, full.Header.Get("ETag"), resp.Header.Get("ETag"))
+		assert.NotNil(t, schema.Directives["goField"], "the executable schema is left untouched")
+	})
+
+	t.Run("introspection", func(t *testing.T) {
+		var introspected *graphql.OperationContext
+		srv := handler.New(&graphql.ExecutableSchemaMock{
+			SchemaFunc: func() *ast.Schema {
+				return schema
+			},
+			ExecFunc: func(ctx context.Context) graphql.ResponseHandler {
+				introspected = graphql.GetOperationContext(ctx)
+				return graphql.OneShot(&graphql.Response{Data: []byte(`{"__schema":{"queryType":{"name":"Query"},"directives":[{"name":"goField"},{"name":"auth"},{"name":"deprecated"}]}}`)})
+			},
+		})
+		srv.Use(extension.IntrospectionPolicy{})
+
+		resp, body := do(t, srv.SchemaHandler(handler.SchemaConfig{
+			Format:             handler.SchemaFormatIntrospection,
+			InternalDirectives: []string{"goField", "auth"},
+		}), nil)
+		assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+		assert.Equal(t, `{"data":{"__schema":{"directives":[{"name":"deprecated"}],"queryType":{"name":"Query"}}}}`, body)
+
+		require.NotNil(t, introspected, "the executor answers the introspection query")
+		assert.Equal(t, "IntrospectionQuery", introspected.Operation.Name)
+		assert.False(t, introspected.DisableIntrospection, "the extensions of the server do not apply")
+	})
+
+	t.Run("next", func(t *testing.T) {
+		h := srv.SchemaHandler(handler.SchemaConfig{Next: func(c *fiber.Ctx) bool { return c.Get("X-Token") != "secret" }})
+
+		resp, _ := do(t, h, nil)
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+		resp, _ = do(t, h, map[string]string{"X-Token": "secret"})
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	})
+}
@@ -2,34 +2,54 @@ package handler
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"runtime/debug"
+	"time"
 
 	"github.com/99designs/gqlgen/graphql"
 	"github.com/99designs/gqlgen/graphql/executor"
 	"github.com/99designs/gqlgen/graphql/handler/extension"
 	"github.com/99designs/gqlgen/graphql/handler/lru"
 	fibergqlgen "github.com/NickTaporuk/fiber-gqlgen"
+	fiberextension "github.com/NickTaporuk/fiber-gqlgen/handler/extension"
 	"github.com/NickTaporuk/fiber-gqlgen/handler/transport"
+	"github.com/gofiber/fiber/v2"
 	"github.com/vektah/gqlparser/v2/gqlerror"
 )
 
 type Server struct {
-	transports []fibergqlgen.Transport
-	exec       *executor.Executor
+	transports      []fibergqlgen.Transport
+	schema          graphql.ExecutableSchema
+	exec            *executor.Executor
+	recoveredStatus int
+	panicHook       PanicHook
+	locals          []string
+
+	persistedQueries *persistedQueryCache
 }
 
+// PanicHook is called with the value and the stack trace of a panic recovered by ServeGraphQL,
+// before the recovered error is presented to the client.
+type PanicHook func(c *fiber.Ctx, err interface{}, stack []byte)
+
 func New(es graphql.ExecutableSchema) *Server {
 	return &Server{
-		exec: executor.New(es),
+		schema:          es,
+		exec:            executor.New(es),
+		recoveredStatus: fiber.StatusUnprocessableEntity,
 	}
 }
 
+// NewDefaultServer creates a server with the websocket, OPTIONS, GET, POST and multipart form transports,
+// query and APQ caches. Introspection is allowed for every request by an extension.IntrospectionPolicy of
+// this module, Use another IntrospectionPolicy to restrict it, the one added last decides.
 func NewDefaultServer(es graphql.ExecutableSchema) *Server {
 	srv := New(es)
 
-	/*srv.AddTransport(transport.Websocket{
+	srv.AddTransport(transport.Websocket{
 		KeepAlivePingInterval: 10 * time.Second,
-	})*/
+	})
 	srv.AddTransport(transport.Options{})
 	srv.AddTransport(transport.GET{})
 	srv.AddTransport(transport.POST{})
@@ -37,15 +57,22 @@ func NewDefaultServer(es graphql.ExecutableSchema) *Server {
 
 	srv.SetQueryCache(lru.New(1000))
 
-	srv.Use(extension.Introspection{})
-	srv.Use(extension.AutomaticPersistedQuery{
-		Cache: lru.New(100),
+	srv.Use(fiberextension.IntrospectionPolicy{
+		Allow: func(ctx context.Context, rc *graphql.OperationContext) bool { return true },
 	})
+	srv.SetPersistedQueryCache(lru.New(100))
 
 	return srv
 }
 
+// AddTransport adds a transport serving the requests it supports, it panics when the transport
+// implements fibergqlgen.TransportValidator and its configuration is invalid.
 func (s *Server) AddTransport(transport fibergqlgen.Transport) {
+	if v, ok := transport.(fibergqlgen.TransportValidator); ok {
+		if err := v.Validate(); err != nil {
+			panic(err)
+		}
+	}
 	s.transports = append(s.transports, transport)
 }
 
@@ -57,11 +84,70 @@ func (s *Server) SetRecoverFunc(f graphql.RecoverFunc) {
 	s.exec.SetRecoverFunc(f)
 }
 
+// SetRecoveredStatus sets the status of the response written when a panic is recovered
+// from a transport, 422 Unprocessable Entity by default.
+func (s *Server) SetRecoveredStatus(code int) {
+	s.recoveredStatus = code
+}
+
+// SetPanicHook sets a hook that is called for every panic recovered from a transport, eg to
+// report it along with its stack trace.
+func (s *Server) SetPanicHook(f PanicHook) {
+	s.panicHook = f
+}
+
+// SetRequestInfoLocals sets the keys of the c.Locals values copied into the fibergqlgen.RequestInfo
+// of every request.
+func (s *Server) SetRequestInfoLocals(keys ...string) {
+	s.locals = keys
+}
+
 func (s *Server) SetQueryCache(cache graphql.Cache) {
 	s.exec.SetQueryCache(cache)
 }
 
+// SetPersistedQueryCache sets the cache of the automatic persisted queries, eg a store.Cache shared
+// between the replicas of a service. The AutomaticPersistedQuery extension is added to the server by
+// the first call, later calls replace the cache of NewDefaultServer or of an earlier call.
+func (s *Server) SetPersistedQueryCache(cache graphql.Cache) {
+	if s.persistedQueries == nil {
+		s.persistedQueries = &persistedQueryCache{}
+		s.Use(extension.AutomaticPersistedQuery{Cache: s.persistedQueries})
+	}
+	s.persistedQueries.cache = cache
+}
+
+// persistedQueryCache is the cache of the AutomaticPersistedQuery extension added by
+// SetPersistedQueryCache, it delegates to the cache set last. The documents of a TrustedDocuments
+// extension added after it are found by their hash first, as the AutomaticPersistedQuery runs
+// before TrustedDocuments can fill in the query.
+type persistedQueryCache struct {
+	cache    graphql.Cache
+	manifest *fiberextension.Manifest
+}
+
+func (c *persistedQueryCache) Get(ctx context.Context, key string) (interface{}, bool) {
+	if c.manifest != nil {
+		if document, ok := c.manifest.Get(key); ok {
+			return document, true
+		}
+	}
+	return c.cache.Get(ctx, key)
+}
+
+func (c *persistedQueryCache) Add(ctx context.Context, key string, value interface{}) {
+	c.cache.Add(ctx, key, value)
+}
+
 func (s *Server) Use(extension graphql.HandlerExtension) {
+	if s.persistedQueries != nil {
+		switch t := extension.(type) {
+		case fiberextension.TrustedDocuments:
+			s.persistedQueries.manifest = t.Manifest
+		case *fiberextension.TrustedDocuments:
+			s.persistedQueries.manifest = t.Manifest
+		}
+	}
 	s.exec.Use(extension)
 }
 
@@ -94,30 +180,59 @@ func (s *Server) getTransport(c *fiber.Ctx) fibergqlgen.Transport {
 	return nil
 }
 
-func (s *Server) ServeGraphQL(c *fiber.Ctx) error {
-	var dErr error
+// ServeGraphQL serves the request with the first transport supporting it.
+//
+// Operations run on c.UserContext(), so values and deadlines set by earlier fiber handlers reach the
+// resolvers, and the context is cancelled once a buffered response is complete. fasthttp does not tell
+// a handler that its client went away, so a query or mutation answered with a buffered response runs
+// to completion even when the client disconnects, bound it with the OperationTimeout extension. Only
+// streamed and hijacked responses, which outlive the handler, notice a client that went away: their
+// transports cancel the operation when a write to the client fails.
+func (s *Server) ServeGraphQL(c *fiber.Ctx) (err error) {
 	defer func() {
-		if err := recover(); err != nil {
-			err := s.exec.PresentRecoveredError(c.Context(), err)
-			resp := &graphql.Response{Errors: []*gqlerror.Error{err}}
-			c.Status(fiber.StatusUnprocessableEntity)
-
-			dErr = c.JSON(resp)
+		if r := recover(); r != nil {
+			err = s.recovered(c, r, debug.Stack())
 		}
 	}()
 
-	graphql.StartOperationTrace(c.Context())
+	// Streamed and hijacked responses cancel the context themselves, see above.
+	ctx, cancel := context.WithCancel(graphql.StartOperationTrace(c.UserContext()))
+	ctx = fibergqlgen.WithRequestInfo(ctx, fibergqlgen.NewRequestInfo(c, s.locals...))
+	ctx = fibergqlgen.WithResponse(ctx)
+	ctx = fibergqlgen.WithTransports(ctx, s.transports)
+	defer func() {
+		if !c.Response().IsBodyStream() && !c.Context().Hijacked() {
+			cancel()
+		}
+	}()
+	c.SetUserContext(ctx)
 
 	transport := s.getTransport(c)
 	if transport == nil {
 		return sendErrorf(c, fiber.StatusBadRequest, "transport not supported")
 	}
 
-	if dErr != nil {
-		return dErr
+	return transport.Do(c, s.exec)
+}
+
+// recovered replaces whatever the transport wrote so far with the presented error of the
+// recovered panic. Headers set by earlier handlers, eg for CORS, are kept.
+func (s *Server) recovered(c *fiber.Ctx, r interface{}, stack []byte) error {
+	if s.panicHook != nil {
+		s.panicHook(c, r, stack)
+	}
+
+	var gqlErr *gqlerror.Error
+	err := s.exec.PresentRecoveredError(c.UserContext(), r)
+	switch {
+	case err == nil:
+		gqlErr = &gqlerror.Error{Message: "internal system error"}
+	case !errors.As(err, &gqlErr):
+		gqlErr = &gqlerror.Error{Message: err.Error()}
 	}
 
-	return transport.Do(c, s.exec)
+	c.Response().ResetBody()
+	return sendError(c, s.recoveredStatus, gqlErr)
 }
 
 func sendError(c *fiber.Ctx, code int, errors ...*gqlerror.Error) error {
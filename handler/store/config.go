@@ -0,0 +1,57 @@
+package store
+
+import "time"
+
+// Config defines the config for a Cache.
+type Config struct {
+	// Prefix defines the prefix added to every key written to the store, so that several
+	// caches can share one store.
+	//
+	// Optional. Default: ""
+	Prefix string
+
+	// TTL defines how long an entry is kept by the store.
+	//
+	// Optional. Default: 24 * time.Hour
+	TTL time.Duration
+
+	// MaxValueSize defines the size in bytes of the biggest value that is stored, bigger
+	// values are not cached at all.
+	//
+	// Optional. Default: 1MB
+	MaxValueSize int
+
+	// OnError defines a function called with the errors returned by the store. Failing reads
+	// are counted as misses and failing writes are dropped.
+	//
+	// Optional. Default: nil
+	OnError func(err error)
+}
+
+var ConfigDefault = Config{
+	Prefix:       "",
+	TTL:          24 * time.Hour,
+	MaxValueSize: 1 << 20,
+	OnError:      nil,
+}
+
+func configDefault(config ...Config) Config {
+	// Return default config if nothing provided
+	if len(config) < 1 {
+		return ConfigDefault
+	}
+
+	// Override default config
+	cfg := config[0]
+
+	// Set default values
+	if cfg.TTL <= 0 {
+		cfg.TTL = ConfigDefault.TTL
+	}
+
+	if cfg.MaxValueSize <= 0 {
+		cfg.MaxValueSize = ConfigDefault.MaxValueSize
+	}
+
+	return cfg
+}
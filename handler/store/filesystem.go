@@ -0,0 +1,203 @@
+package store
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	filesystemExt    = ".entry"
+	filesystemTmp    = ".tmp-"
+	filesystemTmpAge = 10 * time.Minute
+)
+
+// Filesystem is a KV store keeping every entry in its own file of a directory, which can be
+// a volume shared by the replicas of a service.
+//
+// Files are written to a temporary name and renamed, so readers never see a partial entry. Temporary
+// files left behind by a crash are removed when the store is created and whenever entries are evicted.
+type Filesystem struct {
+	dir string
+
+	// MaxEntries bounds the number of files in the directory, the least recently written
+	// entries are removed to make room for new ones. Zero means no bound.
+	//
+	// The entries are counted in-process and the directory is only scanned once the count reaches
+	// MaxEntries, a tenth of the entries is evicted at once so the scans are amortized over many
+	// writes. Replicas sharing the directory count their own writes, so the bound is a soft one.
+	MaxEntries int
+
+	mu      sync.Mutex
+	count   int
+	counted bool
+}
+
+var _ KV = &Filesystem{}
+
+// NewFilesystem creates a Filesystem store in dir, creating the directory if needed
+func NewFilesystem(dir string) (*Filesystem, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	f := &Filesystem{dir: dir}
+	if _, err := f.scan(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func (f *Filesystem) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	path := f.path(key)
+	b, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	if len(b) < 8 {
+		return nil, false, errors.New("store: corrupted entry " + path)
+	}
+
+	expires := time.Unix(0, int64(binary.BigEndian.Uint64(b[:8])))
+	if !time.Now().Before(expires) {
+		if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return nil, false, err
+		}
+		return nil, false, nil
+	}
+
+	return b[8:], true, nil
+}
+
+func (f *Filesystem) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	if f.MaxEntries > 0 {
+		if err := f.reserve(); err != nil {
+			return err
+		}
+	}
+
+	b := make([]byte, 8+len(value))
+	binary.BigEndian.PutUint64(b[:8], uint64(time.Now().Add(ttl).UnixNano()))
+	copy(b[8:], value)
+
+	tmp, err := os.CreateTemp(f.dir, filesystemTmp)
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+
+	if err := os.Rename(tmp.Name(), f.path(key)); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+
+	return nil
+}
+
+// reserve makes room for a new entry, evicting the least recently written entries once the count
+// of entries reaches MaxEntries
+func (f *Filesystem) reserve() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if !f.counted || f.count >= f.MaxEntries {
+		batch := f.MaxEntries / 10
+		if batch < 1 {
+			batch = 1
+		}
+		n, err := f.evict(f.MaxEntries-1, f.MaxEntries-batch)
+		if err != nil {
+			return err
+		}
+		f.count, f.counted = n, true
+	}
+	f.count++
+
+	return nil
+}
+
+// evict removes the least recently written entries until keep are left, when there are more than
+// limit of them. It returns the number of entries left.
+func (f *Filesystem) evict(limit, keep int) (int, error) {
+	entries, err := f.scan()
+	if err != nil {
+		return 0, err
+	}
+	if len(entries) <= limit {
+		return len(entries), nil
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].modTime.Before(entries[j].modTime)
+	})
+	for _, e := range entries[:len(entries)-keep] {
+		if err := os.Remove(e.path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return 0, err
+		}
+	}
+
+	return keep, nil
+}
+
+type filesystemEntry struct {
+	path    string
+	modTime time.Time
+}
+
+// scan lists the entries of the directory and removes the temporary files left behind by writes that
+// did not complete
+func (f *Filesystem) scan() ([]filesystemEntry, error) {
+	dirEntries, err := os.ReadDir(f.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []filesystemEntry
+	for _, e := range dirEntries {
+		if e.IsDir() {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		path := filepath.Join(f.dir, e.Name())
+		switch {
+		case strings.HasPrefix(e.Name(), filesystemTmp):
+			if time.Since(info.ModTime()) > filesystemTmpAge {
+				if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+					return nil, err
+				}
+			}
+		case strings.HasSuffix(e.Name(), filesystemExt):
+			entries = append(entries, filesystemEntry{path: path, modTime: info.ModTime()})
+		}
+	}
+
+	return entries, nil
+}
+
+// path names the file of key by its hash, as keys can hold any character
+func (f *Filesystem) path(key string) string {
+	h := sha256.Sum256([]byte(key))
+	return filepath.Join(f.dir, hex.EncodeToString(h[:])+filesystemExt)
+}
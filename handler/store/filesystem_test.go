@@ -0,0 +1,122 @@
+package store_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/NickTaporuk/fiber-gqlgen/handler/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFilesystem(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("get and set", func(t *testing.T) {
+		fs, err := store.NewFilesystem(t.TempDir())
+		require.NoError(t, err)
+
+		_, ok, err := fs.Get(ctx, "hash")
+		require.NoError(t, err)
+		assert.False(t, ok)
+
+		require.NoError(t, fs.Set(ctx, "hash", []byte("{ name }"), time.Minute))
+		value, ok, err := fs.Get(ctx, "hash")
+		require.NoError(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, "{ name }", string(value))
+	})
+
+	t.Run("expired entries are removed", func(t *testing.T) {
+		dir := t.TempDir()
+		fs, err := store.NewFilesystem(dir)
+		require.NoError(t, err)
+
+		require.NoError(t, fs.Set(ctx, "hash", []byte("{ name }"), -time.Second))
+		_, ok, err := fs.Get(ctx, "hash")
+		require.NoError(t, err)
+		assert.False(t, ok)
+
+		files, err := os.ReadDir(dir)
+		require.NoError(t, err)
+		assert.Empty(t, files)
+	})
+
+	t.Run("max entries", func(t *testing.T) {
+		dir := t.TempDir()
+		fs, err := store.NewFilesystem(dir)
+		require.NoError(t, err)
+		fs.MaxEntries = 2
+
+		for _, key := range []string{"a", "b", "c"} {
+			require.NoError(t, fs.Set(ctx, key, []byte(key), time.Minute))
+			time.Sleep(10 * time.Millisecond)
+		}
+
+		files, err := os.ReadDir(dir)
+		require.NoError(t, err)
+		assert.Len(t, files, 2)
+
+		_, ok, err := fs.Get(ctx, "a")
+		require.NoError(t, err)
+		assert.False(t, ok)
+		_, ok, err = fs.Get(ctx, "c")
+		require.NoError(t, err)
+		assert.True(t, ok)
+	})
+
+	t.Run("evicts in batches", func(t *testing.T) {
+		dir := t.TempDir()
+		fs, err := store.NewFilesystem(dir)
+		require.NoError(t, err)
+		fs.MaxEntries = 20
+
+		for i := 0; i < 20; i++ {
+			require.NoError(t, fs.Set(ctx, fmt.Sprint(i), []byte("{ name }"), time.Minute))
+		}
+		files, err := os.ReadDir(dir)
+		require.NoError(t, err)
+		assert.Len(t, files, 20)
+
+		// a tenth of the entries is evicted to make room for the next one
+		require.NoError(t, fs.Set(ctx, "20", []byte("{ name }"), time.Minute))
+		files, err = os.ReadDir(dir)
+		require.NoError(t, err)
+		assert.Len(t, files, 19)
+	})
+
+	t.Run("stale temporary files are removed", func(t *testing.T) {
+		dir := t.TempDir()
+		stale := filepath.Join(dir, ".tmp-stale")
+		fresh := filepath.Join(dir, ".tmp-fresh")
+		require.NoError(t, os.WriteFile(stale, []byte("partial"), 0o644))
+		require.NoError(t, os.WriteFile(fresh, []byte("partial"), 0o644))
+		old := time.Now().Add(-time.Hour)
+		require.NoError(t, os.Chtimes(stale, old, old))
+
+		_, err := store.NewFilesystem(dir)
+		require.NoError(t, err)
+
+		_, err = os.Stat(stale)
+		assert.True(t, os.IsNotExist(err))
+		_, err = os.Stat(fresh)
+		assert.NoError(t, err, "a write may still be in progress")
+	})
+
+	t.Run("shared between caches", func(t *testing.T) {
+		dir := t.TempDir()
+		fs1, err := store.NewFilesystem(dir)
+		require.NoError(t, err)
+		fs2, err := store.NewFilesystem(dir)
+		require.NoError(t, err)
+
+		store.New(fs1).Add(ctx, "hash", "{ name }")
+		value, ok := store.New(fs2).Get(ctx, "hash")
+		assert.True(t, ok)
+		assert.Equal(t, "{ name }", value)
+	})
+}
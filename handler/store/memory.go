@@ -0,0 +1,97 @@
+package store
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Memory is an in-process KV store, meant as a stand-in for a shared store in tests.
+type Memory struct {
+	// MaxEntries bounds the number of entries, the entry closest to expiring is evicted to
+	// make room for a new one. Zero means no bound.
+	MaxEntries int
+
+	// Now returns the current time, it can be replaced to control expiry in tests.
+	Now func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]memoryEntry
+}
+
+type memoryEntry struct {
+	value   []byte
+	expires time.Time
+}
+
+var _ KV = &Memory{}
+
+// NewMemory creates an empty Memory store
+func NewMemory() *Memory {
+	return &Memory{Now: time.Now}
+}
+
+func (m *Memory) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	entry, ok := m.entries[key]
+	if !ok {
+		return nil, false, nil
+	}
+	if !m.now().Before(entry.expires) {
+		delete(m.entries, key)
+		return nil, false, nil
+	}
+
+	return entry.value, true, nil
+}
+
+func (m *Memory) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.entries == nil {
+		m.entries = map[string]memoryEntry{}
+	}
+
+	if _, ok := m.entries[key]; !ok && m.MaxEntries > 0 && len(m.entries) >= m.MaxEntries {
+		m.evict()
+	}
+
+	m.entries[key] = memoryEntry{
+		value:   append([]byte(nil), value...),
+		expires: m.now().Add(ttl),
+	}
+
+	return nil
+}
+
+// Len returns the number of entries, expired ones included until they are read or evicted
+func (m *Memory) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return len(m.entries)
+}
+
+func (m *Memory) evict() {
+	var (
+		oldest  string
+		expires time.Time
+		found   bool
+	)
+	for key, entry := range m.entries {
+		if !found || entry.expires.Before(expires) {
+			oldest, expires, found = key, entry.expires, true
+		}
+	}
+	delete(m.entries, oldest)
+}
+
+func (m *Memory) now() time.Time {
+	if m.Now == nil {
+		return time.Now()
+	}
+	return m.Now()
+}
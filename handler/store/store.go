@@ -0,0 +1,102 @@
+// Package store provides graphql.Cache implementations backed by stores shared between
+// replicas, such as the filesystem or a Redis or memcached client, for the query hashes of
+// extension.AutomaticPersistedQuery. Server.SetPersistedQueryCache sets one on a server.
+//
+// The stores keep strings only, the parsed documents of Server.SetQueryCache can not be
+// serialized and belong in an in-process cache like lru.
+package store
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql"
+)
+
+// KV is the interface a key/value client implements to back a Cache.
+type KV interface {
+	// Get returns the value stored under key, ok is false when there is none or it expired.
+	Get(ctx context.Context, key string) (value []byte, ok bool, err error)
+
+	// Set stores the value under key for ttl.
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+}
+
+// Stats counts the lookups of a Cache.
+type Stats struct {
+	Hits   uint64
+	Misses uint64
+	Errors uint64
+}
+
+// Cache adapts a KV store to graphql.Cache.
+type Cache struct {
+	kv  KV
+	cfg Config
+
+	hits   atomic.Uint64
+	misses atomic.Uint64
+	errors atomic.Uint64
+}
+
+var _ graphql.Cache = &Cache{}
+
+// New creates a Cache storing its entries in kv
+func New(kv KV, config ...Config) *Cache {
+	return &Cache{
+		kv:  kv,
+		cfg: configDefault(config...),
+	}
+}
+
+func (c *Cache) Get(ctx context.Context, key string) (interface{}, bool) {
+	value, ok, err := c.kv.Get(ctx, c.cfg.Prefix+key)
+	if err != nil {
+		c.error(err)
+		ok = false
+	}
+	if !ok {
+		c.misses.Add(1)
+		return nil, false
+	}
+
+	c.hits.Add(1)
+	return string(value), true
+}
+
+// Add stores value, which must be a string or a []byte, under key. Other values are ignored.
+func (c *Cache) Add(ctx context.Context, key string, value interface{}) {
+	var b []byte
+	switch v := value.(type) {
+	case string:
+		b = []byte(v)
+	case []byte:
+		b = v
+	default:
+		return
+	}
+	if len(b) > c.cfg.MaxValueSize {
+		return
+	}
+
+	if err := c.kv.Set(ctx, c.cfg.Prefix+key, b, c.cfg.TTL); err != nil {
+		c.error(err)
+	}
+}
+
+// Stats returns the number of hits, misses and errors since the cache was created
+func (c *Cache) Stats() Stats {
+	return Stats{
+		Hits:   c.hits.Load(),
+		Misses: c.misses.Load(),
+		Errors: c.errors.Load(),
+	}
+}
+
+func (c *Cache) error(err error) {
+	c.errors.Add(1)
+	if c.cfg.OnError != nil {
+		c.cfg.OnError(err)
+	}
+}
@@ -0,0 +1,101 @@
+package store_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/NickTaporuk/fiber-gqlgen/handler/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type failingKV struct{}
+
+func (failingKV) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	return nil, false, errors.New("get failed")
+}
+
+func (failingKV) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return errors.New("set failed")
+}
+
+func TestCache(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("hits and misses", func(t *testing.T) {
+		mem := store.NewMemory()
+		cache := store.New(mem, store.Config{Prefix: "apq:"})
+
+		_, ok := cache.Get(ctx, "hash")
+		assert.False(t, ok)
+
+		cache.Add(ctx, "hash", "{ name }")
+		value, ok := cache.Get(ctx, "hash")
+		assert.True(t, ok)
+		assert.Equal(t, "{ name }", value)
+
+		_, ok, err := mem.Get(ctx, "apq:hash")
+		require.NoError(t, err)
+		assert.True(t, ok)
+
+		assert.Equal(t, store.Stats{Hits: 1, Misses: 1}, cache.Stats())
+	})
+
+	t.Run("ttl", func(t *testing.T) {
+		now := time.Now()
+		mem := store.NewMemory()
+		mem.Now = func() time.Time { return now }
+		cache := store.New(mem, store.Config{TTL: time.Minute})
+
+		cache.Add(ctx, "hash", "{ name }")
+		_, ok := cache.Get(ctx, "hash")
+		assert.True(t, ok)
+
+		now = now.Add(time.Minute)
+		_, ok = cache.Get(ctx, "hash")
+		assert.False(t, ok)
+		assert.Equal(t, 0, mem.Len())
+	})
+
+	t.Run("size bounds", func(t *testing.T) {
+		mem := store.NewMemory()
+		mem.MaxEntries = 2
+		cache := store.New(mem, store.Config{MaxValueSize: 10})
+
+		cache.Add(ctx, "big", strings.Repeat("a", 11))
+		_, ok := cache.Get(ctx, "big")
+		assert.False(t, ok)
+
+		cache.Add(ctx, "a", "a")
+		cache.Add(ctx, "b", "b")
+		cache.Add(ctx, "c", "c")
+		assert.Equal(t, 2, mem.Len())
+		_, ok = cache.Get(ctx, "c")
+		assert.True(t, ok)
+	})
+
+	t.Run("values that are not strings are ignored", func(t *testing.T) {
+		mem := store.NewMemory()
+		cache := store.New(mem)
+
+		cache.Add(ctx, "doc", struct{}{})
+		assert.Equal(t, 0, mem.Len())
+	})
+
+	t.Run("store errors", func(t *testing.T) {
+		var errs []string
+		cache := store.New(failingKV{}, store.Config{OnError: func(err error) {
+			errs = append(errs, err.Error())
+		}})
+
+		cache.Add(ctx, "hash", "{ name }")
+		_, ok := cache.Get(ctx, "hash")
+		assert.False(t, ok)
+
+		assert.Equal(t, []string{"set failed", "get failed"}, errs)
+		assert.Equal(t, store.Stats{Misses: 1, Errors: 2}, cache.Stats())
+	})
+}
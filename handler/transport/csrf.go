@@ -0,0 +1,58 @@
+package transport
+
+import (
+	"mime"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// defaultPreflightHeaders are the headers a browser can only send after a CORS preflight, the same
+// ones Apollo Server checks for its CSRF prevention.
+var defaultPreflightHeaders = []string{"X-Apollo-Operation-Name", "Apollo-Require-Preflight"}
+
+// simpleContentTypes are the content types a browser sends cross-site without a CORS preflight
+var simpleContentTypes = map[string]bool{
+	"application/x-www-form-urlencoded": true,
+	"multipart/form-data":               true,
+	"text/plain":                        true,
+}
+
+// preflighted reports whether the request can only have been sent after a CORS preflight, either
+// because its Content-Type is not a simple one or because it carries one of the preflight headers.
+func preflighted(c *fiber.Ctx, headers []string) bool {
+	if contentType := c.Get("Content-Type"); contentType != "" {
+		mediaType, _, err := mime.ParseMediaType(contentType)
+		// the parameters do not matter to browsers, only a media type they can not parse is preflighted
+		if err != nil && err != mime.ErrInvalidMediaParameter || !simpleContentTypes[mediaType] {
+			return true
+		}
+	}
+	return hasPreflightHeader(c, headers)
+}
+
+// hasPreflightHeader reports whether the request carries a non-empty value for one of headers, or
+// for one of defaultPreflightHeaders when headers is empty. A cross-site request can only set such a
+// header once the browser's preflight succeeded, so the request is not a forged simple request.
+func hasPreflightHeader(c *fiber.Ctx, headers []string) bool {
+	if len(headers) == 0 {
+		headers = defaultPreflightHeaders
+	}
+	for _, header := range headers {
+		if c.Get(header) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// csrfBlocked answers a request rejected as a potential cross-site request forgery
+func csrfBlocked(c *fiber.Ctx, headers []string) error {
+	if len(headers) == 0 {
+		headers = defaultPreflightHeaders
+	}
+	c.Set("Content-Type", mediaTypeJSON)
+	c.Status(fiber.StatusBadRequest)
+	return writeJsonErrorf(c, "this operation has been blocked as a potential Cross-Site Request Forgery (CSRF), "+
+		"please provide a non-empty value for one of the following headers: %s", strings.Join(headers, ", "))
+}
@@ -0,0 +1,108 @@
+package transport_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	fibergqlgen "github.com/NickTaporuk/fiber-gqlgen"
+	"github.com/NickTaporuk/fiber-gqlgen/handler/testserver"
+	"github.com/NickTaporuk/fiber-gqlgen/handler/transport"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const csrfBlockedBody = `{"errors":[{"message":"this operation has been blocked as a potential Cross-Site Request Forgery (CSRF), please provide a non-empty value for one of the following headers: X-Apollo-Operation-Name, Apollo-Require-Preflight"}],"data":null}`
+
+func TestCSRFPrevention(t *testing.T) {
+	do := func(t *testing.T, tr fibergqlgen.Transport, req *http.Request) (int, string) {
+		h := testserver.New()
+		h.AddTransport(tr)
+
+		app := fiber.New()
+		app.All("/graphql", h.ServeGraphQL)
+
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+
+		b, err := ioutil.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return resp.StatusCode, string(b)
+	}
+
+	get := func(headers map[string]string) *http.Request {
+		req := httptest.NewRequest("GET", "/graphql?query={name}", nil)
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+		return req
+	}
+
+	multipartForm := func(headers map[string]string) *http.Request {
+		var body bytes.Buffer
+		w := multipart.NewWriter(&body)
+		require.NoError(t, w.WriteField("operations", `{"query":"{ name }"}`))
+		require.NoError(t, w.WriteField("map", `{}`))
+		require.NoError(t, w.Close())
+
+		req := httptest.NewRequest("POST", "/graphql", &body)
+		req.Header.Set("Content-Type", w.FormDataContentType())
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+		return req
+	}
+
+	t.Run("GET", func(t *testing.T) {
+		code, body := do(t, transport.GET{CSRFPrevention: true}, get(nil))
+		assert.Equal(t, http.StatusBadRequest, code)
+		assert.Equal(t, csrfBlockedBody, body)
+
+		code, _ = do(t, transport.GET{CSRFPrevention: true}, get(map[string]string{"Content-Type": "text/plain"}))
+		assert.Equal(t, http.StatusBadRequest, code, "simple content types are not enough")
+
+		code, _ = do(t, transport.GET{CSRFPrevention: true}, get(map[string]string{"Content-Type": "Text/Plain; foo"}))
+		assert.Equal(t, http.StatusBadRequest, code, "invalid parameters do not make a content type non-simple")
+
+		code, _ = do(t, transport.GET{CSRFPrevention: true}, get(map[string]string{"Content-Type": "text/plain/json"}))
+		assert.Equal(t, http.StatusOK, code, "browsers preflight media types they can not parse")
+
+		code, body = do(t, transport.GET{CSRFPrevention: true}, get(map[string]string{"Apollo-Require-Preflight": "true"}))
+		assert.Equal(t, http.StatusOK, code)
+		assert.Equal(t, `{"data":{"name":"test"}}`, body)
+
+		code, _ = do(t, transport.GET{CSRFPrevention: true}, get(map[string]string{"Content-Type": "application/json"}))
+		assert.Equal(t, http.StatusOK, code)
+
+		code, _ = do(t, transport.GET{}, get(nil))
+		assert.Equal(t, http.StatusOK, code, "the guard is opt-in")
+	})
+
+	t.Run("MultipartForm", func(t *testing.T) {
+		code, body := do(t, transport.MultipartForm{CSRFPrevention: true}, multipartForm(nil))
+		assert.Equal(t, http.StatusBadRequest, code)
+		assert.Equal(t, csrfBlockedBody, body)
+
+		code, body = do(t, transport.MultipartForm{CSRFPrevention: true}, multipartForm(map[string]string{"X-Apollo-Operation-Name": "A"}))
+		assert.Equal(t, http.StatusOK, code)
+		assert.Equal(t, `{"data":{"name":"test"}}`, body)
+
+		code, _ = do(t, transport.MultipartForm{}, multipartForm(nil))
+		assert.Equal(t, http.StatusOK, code, "the guard is opt-in")
+	})
+
+	t.Run("custom preflight headers", func(t *testing.T) {
+		tr := transport.GET{CSRFPrevention: true, PreflightHeaders: []string{"X-Requested-With"}}
+
+		code, body := do(t, tr, get(map[string]string{"Apollo-Require-Preflight": "true"}))
+		assert.Equal(t, http.StatusBadRequest, code)
+		assert.Contains(t, body, "one of the following headers: X-Requested-With")
+
+		code, _ = do(t, tr, get(map[string]string{"X-Requested-With": "XMLHttpRequest"}))
+		assert.Equal(t, http.StatusOK, code)
+	})
+}
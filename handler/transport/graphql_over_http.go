@@ -0,0 +1,130 @@
+package transport
+
+import (
+	"mime"
+	"strconv"
+	"strings"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/gofiber/fiber/v2"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+const (
+	mediaTypeJSON            = "application/json"
+	mediaTypeGraphQLResponse = "application/graphql-response+json"
+)
+
+// overHTTP applies the rules of https://graphql.github.io/graphql-over-http/draft/ for the media type
+// negotiated with the client.
+//
+// Responses in application/graphql-response+json always follow the spec. Responses in application/json
+// keep the legacy status codes and body shape unless strict is set.
+type overHTTP struct {
+	mediaType string
+	strict    bool
+	// stream is the streamed media type preferred by the client, empty when it prefers a JSON response
+	stream string
+}
+
+// negotiate picks the response media type from the Accept header. It reports false when the client
+// accepts neither of the GraphQL response media types, streamed media types listed in also are
+// accepted too and answered with application/json when the response ends up not being streamed.
+// A streamed media type is only reported in stream when it has the highest q of all accepted types.
+func negotiate(c *fiber.Ctx, strict bool, also ...string) (overHTTP, bool) {
+	o := overHTTP{mediaType: mediaTypeJSON, strict: strict}
+
+	accept := c.Get("Accept")
+	if accept == "" {
+		return o, true
+	}
+
+	bestQ := -1.0
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		q := 1.0
+		if v, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(v, 64); err != nil || q <= 0 {
+				continue
+			}
+		}
+		if q <= bestQ {
+			continue
+		}
+
+		switch mediaType {
+		case mediaTypeGraphQLResponse:
+			o.mediaType, o.stream = mediaTypeGraphQLResponse, ""
+		case mediaTypeJSON, "application/*", "*/*":
+			o.mediaType, o.stream = mediaTypeJSON, ""
+		default:
+			if !contains(also, mediaType) {
+				continue
+			}
+			o.mediaType, o.stream = mediaTypeJSON, mediaType
+		}
+		bestQ = q
+	}
+
+	return o, bestQ >= 0
+}
+
+// contentType returns the value of the Content-Type header for JSON responses
+func (o overHTTP) contentType() string {
+	if o.mediaType == mediaTypeGraphQLResponse {
+		return mediaTypeGraphQLResponse + "; charset=utf-8"
+	}
+	if o.strict {
+		return mediaTypeJSON + "; charset=utf-8"
+	}
+	return mediaTypeJSON
+}
+
+// spec reports whether the response follows the spec rather than the legacy behaviour
+func (o overHTTP) spec() bool {
+	return o.strict || o.mediaType == mediaTypeGraphQLResponse
+}
+
+// requestErrorStatus is the status of a request that failed before execution, eg on parsing or validation
+func (o overHTTP) requestErrorStatus(errs gqlerror.List) int {
+	switch {
+	case o.mediaType == mediaTypeGraphQLResponse:
+		return fiber.StatusBadRequest
+	case o.strict:
+		return fiber.StatusOK
+	default:
+		return statusFor(errs)
+	}
+}
+
+// writeRequestError writes a response of a request that failed before execution. Following the spec
+// such a response has no data entry at all.
+func (o overHTTP) writeRequestError(c *fiber.Ctx, resp *graphql.Response) error {
+	if !o.spec() {
+		return writeJson(c, resp)
+	}
+
+	b, err := c.App().Config().JSONEncoder(&struct {
+		Errors     gqlerror.List          `json:"errors"`
+		Extensions map[string]interface{} `json:"extensions,omitempty"`
+	}{
+		Errors:     resp.Errors,
+		Extensions: resp.Extensions,
+	})
+	if err != nil {
+		return err
+	}
+
+	applyResponse(c)
+	return c.Send(b)
+}
+
+// notAcceptable answers a client that accepts none of the media types the transport can respond with
+func notAcceptable(c *fiber.Ctx) error {
+	c.Set("Content-Type", mediaTypeJSON)
+	c.Status(fiber.StatusNotAcceptable)
+	return writeJsonErrorf(c, "accept header must allow %s or %s", mediaTypeGraphQLResponse, mediaTypeJSON)
+}
@@ -0,0 +1,324 @@
+package transport_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/NickTaporuk/fiber-gqlgen/handler/testserver"
+	"github.com/NickTaporuk/fiber-gqlgen/handler/transport"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGraphQLOverHTTP(t *testing.T) {
+	newApp := func(strict bool) *fiber.App {
+		h := testserver.New()
+		h.AddTransport(transport.GET{Strict: strict})
+		h.AddTransport(transport.POST{Strict: strict})
+
+		app := fiber.New()
+		app.All("/graphql", h.ServeGraphQL)
+		return app
+	}
+
+	do := func(t *testing.T, app *fiber.App, method, target, accept, body string) (*http.Response, string) {
+		var req *http.Request
+		if method == "GET" {
+			req = httptest.NewRequest(method, target, nil)
+		} else {
+			req = httptest.NewRequest(method, target, strings.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+		}
+		if accept != "" {
+			req.Header.Set("Accept", accept)
+		}
+
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		b, err := ioutil.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return resp, string(b)
+	}
+
+	t.Run("legacy application/json", func(t *testing.T) {
+		app := newApp(false)
+
+		resp, body := do(t, app, "POST", "/graphql", "", `{"query":"{ name }"}`)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+		assert.Equal(t, `{"data":{"name":"test"}}`, body)
+
+		resp, body = do(t, app, "POST", "/graphql", "application/json", `{"query":"!"}`)
+		assert.Equal(t, http.StatusUnprocessableEntity, resp.StatusCode)
+		assert.Equal(t, `{"errors":[{"message":"Unexpected !","locations":[{"line":1,"column":1}],"extensions":{"code":"GRAPHQL_PARSE_FAILED"}}],"data":null}`, body)
+
+		resp, _ = do(t, app, "GET", "/graphql?query="+url.QueryEscape("mutation { name }"), "application/json", "")
+		assert.Equal(t, http.StatusNotAcceptable, resp.StatusCode)
+	})
+
+	for _, strict := range []bool{false, true} {
+		app := newApp(strict)
+		name := "application/graphql-response+json"
+		if strict {
+			name += " strict"
+		}
+
+		t.Run(name, func(t *testing.T) {
+			t.Run("matches the content-type", func(t *testing.T) {
+				resp, body := do(t, app, "POST", "/graphql", "application/graphql-response+json", `{"query":"{ name }"}`)
+				assert.Equal(t, http.StatusOK, resp.StatusCode)
+				assert.Equal(t, "application/graphql-response+json; charset=utf-8", resp.Header.Get("Content-Type"))
+				assert.Equal(t, `{"data":{"name":"test"}}`, body)
+			})
+
+			t.Run("uses 400 without data on JSON parsing failure", func(t *testing.T) {
+				resp, body := do(t, app, "POST", "/graphql", "application/graphql-response+json", `{"query":`)
+				assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+				assert.NotContains(t, body, `"data"`)
+			})
+
+			t.Run("uses 400 without data on invalid parameters", func(t *testing.T) {
+				resp, body := do(t, app, "POST", "/graphql", "application/graphql-response+json", `{"query":{"obj":"ect"}}`)
+				assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+				assert.NotContains(t, body, `"data"`)
+
+				resp, _ = do(t, app, "GET", "/graphql?query={name}&variables=%22string%22", "application/graphql-response+json", "")
+				assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+			})
+
+			t.Run("uses 400 without data on document parsing failure", func(t *testing.T) {
+				resp, body := do(t, app, "POST", "/graphql", "application/graphql-response+json", `{"query":"{"}`)
+				assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+				assert.NotContains(t, body, `"data"`)
+			})
+
+			t.Run("uses 400 without data on document validation failure", func(t *testing.T) {
+				resp, body := do(t, app, "POST", "/graphql", "application/graphql-response+json", `{"query":"{ title }"}`)
+				assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+				assert.NotContains(t, body, `"data"`)
+			})
+
+			t.Run("uses 400 on missing query", func(t *testing.T) {
+				resp, _ := do(t, app, "POST", "/graphql", "application/graphql-response+json", `{"notquery":"{ name }"}`)
+				assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+			})
+
+			t.Run("uses 200 on execution errors", func(t *testing.T) {
+				resp, body := do(t, app, "POST", "/graphql", "application/graphql-response+json", `{"query":"mutation { name }"}`)
+				assert.Equal(t, http.StatusOK, resp.StatusCode)
+				assert.Equal(t, `{"errors":[{"message":"mutations are not supported"}],"data":null}`, body)
+			})
+
+			t.Run("uses 405 for mutations over GET", func(t *testing.T) {
+				resp, _ := do(t, app, "GET", "/graphql?query="+url.QueryEscape("mutation { name }"), "application/graphql-response+json", "")
+				assert.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+				assert.Equal(t, "POST", resp.Header.Get("Allow"))
+			})
+		})
+	}
+
+	t.Run("strict application/json", func(t *testing.T) {
+		app := newApp(true)
+
+		resp, body := do(t, app, "POST", "/graphql", "application/json", `{"query":"{ name }"}`)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "application/json; charset=utf-8", resp.Header.Get("Content-Type"))
+		assert.Equal(t, `{"data":{"name":"test"}}`, body)
+
+		resp, body = do(t, app, "POST", "/graphql", "application/json", `{"query":"{ title }"}`)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.NotContains(t, body, `"data"`)
+
+		resp, _ = do(t, app, "POST", "/graphql", "application/json", `{"query":`)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+		resp, _ = do(t, app, "GET", "/graphql?query="+url.QueryEscape("mutation { name }"), "application/json", "")
+		assert.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+	})
+
+	t.Run("accept negotiation", func(t *testing.T) {
+		app := newApp(false)
+
+		for accept, contentType := range map[string]string{
+			"*/*":           "application/json",
+			"application/*": "application/json",
+			"application/json, application/graphql-response+json;q=0.9": "application/json",
+			"application/json;q=0.9, application/graphql-response+json": "application/graphql-response+json; charset=utf-8",
+		} {
+			resp, _ := do(t, app, "POST", "/graphql", accept, `{"query":"{ name }"}`)
+			assert.Equal(t, http.StatusOK, resp.StatusCode, accept)
+			assert.Equal(t, contentType, resp.Header.Get("Content-Type"), accept)
+		}
+
+		resp, body := do(t, app, "POST", "/graphql", "text/html", `{"query":"{ name }"}`)
+		assert.Equal(t, http.StatusNotAcceptable, resp.StatusCode)
+		assert.Equal(t, `{"errors":[{"message":"accept header must allow application/graphql-response+json or application/json"}],"data":null}`, body)
+	})
+}
+
+// TestGraphQLOverHTTPAudit ports the server audits of the graphql-http reference implementation
+// https://github.com/graphql/graphql-http/blob/main/src/audits/server.ts, run against a strict server
+func TestGraphQLOverHTTPAudit(t *testing.T) {
+	h := testserver.New()
+	h.AddTransport(transport.GET{Strict: true})
+	h.AddTransport(transport.POST{Strict: true})
+
+	app := fiber.New()
+	app.All("/graphql", h.ServeGraphQL)
+
+	post := func(t *testing.T, accept, body string) (*http.Response, string) {
+		req := httptest.NewRequest("POST", "/graphql", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		if accept != "" {
+			req.Header.Set("Accept", accept)
+		}
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		b, err := ioutil.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return resp, string(b)
+	}
+	get := func(t *testing.T, accept string, params url.Values) (*http.Response, string) {
+		req := httptest.NewRequest("GET", "/graphql?"+params.Encode(), nil)
+		if accept != "" {
+			req.Header.Set("Accept", accept)
+		}
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		b, err := ioutil.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return resp, string(b)
+	}
+
+	const (
+		graphqlResponse = "application/graphql-response+json"
+		json            = "application/json"
+	)
+
+	t.Run("SHOULD accept application/graphql-response+json and match the content-type", func(t *testing.T) {
+		resp, _ := post(t, graphqlResponse, `{"query":"{ __typename }"}`)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Contains(t, resp.Header.Get("Content-Type"), graphqlResponse)
+	})
+
+	t.Run("MUST accept application/json and match the content-type", func(t *testing.T) {
+		resp, _ := post(t, json, `{"query":"{ __typename }"}`)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Contains(t, resp.Header.Get("Content-Type"), json)
+	})
+
+	t.Run("SHOULD accept */* and use application/json for the content-type", func(t *testing.T) {
+		resp, _ := post(t, "*/*", `{"query":"{ __typename }"}`)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Contains(t, resp.Header.Get("Content-Type"), json)
+	})
+
+	t.Run("SHOULD assume application/json content-type when accept is missing", func(t *testing.T) {
+		resp, _ := post(t, "", `{"query":"{ __typename }"}`)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Contains(t, resp.Header.Get("Content-Type"), json)
+	})
+
+	t.Run("MUST use utf-8 encoding when responding", func(t *testing.T) {
+		resp, _ := post(t, graphqlResponse, `{"query":"{ __typename }"}`)
+		assert.Contains(t, resp.Header.Get("Content-Type"), "charset=utf-8")
+	})
+
+	t.Run("SHOULD use 400 status code on JSON parsing failure", func(t *testing.T) {
+		for _, accept := range []string{graphqlResponse, json} {
+			resp, _ := post(t, accept, `{ "not a JSON`)
+			assert.Equal(t, http.StatusBadRequest, resp.StatusCode, accept)
+		}
+	})
+
+	t.Run("MUST allow string {query} parameter", func(t *testing.T) {
+		for _, accept := range []string{graphqlResponse, json} {
+			resp, body := post(t, accept, `{"query":"{ __typename }"}`)
+			assert.Equal(t, http.StatusOK, resp.StatusCode, accept)
+			assert.Contains(t, body, `"data"`, accept)
+
+			resp, _ = get(t, accept, url.Values{"query": {"{ __typename }"}})
+			assert.Equal(t, http.StatusOK, resp.StatusCode, accept)
+		}
+	})
+
+	t.Run("SHOULD use 400 status code when {query} is not a string", func(t *testing.T) {
+		for _, body := range []string{`{"query":7}`, `{"query":{"obj":"ect"}}`, `{"query":null}`, `{"notquery":"{ __typename }"}`} {
+			resp, respBody := post(t, graphqlResponse, body)
+			assert.Equal(t, http.StatusBadRequest, resp.StatusCode, body)
+			assert.NotContains(t, respBody, `"data"`, body)
+		}
+	})
+
+	t.Run("MUST allow string and null {operationName} parameter", func(t *testing.T) {
+		for _, body := range []string{`{"query":"query Q { __typename }","operationName":"Q"}`, `{"query":"{ __typename }","operationName":null}`} {
+			resp, _ := post(t, graphqlResponse, body)
+			assert.Equal(t, http.StatusOK, resp.StatusCode, body)
+		}
+	})
+
+	t.Run("SHOULD use 400 status code when {operationName} is not a string", func(t *testing.T) {
+		resp, _ := post(t, graphqlResponse, `{"query":"{ __typename }","operationName":7}`)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("MUST allow null and map {variables} parameter", func(t *testing.T) {
+		for _, body := range []string{`{"query":"{ __typename }","variables":null}`, `{"query":"{ __typename }","variables":{"some":"value"}}`} {
+			resp, _ := post(t, graphqlResponse, body)
+			assert.Equal(t, http.StatusOK, resp.StatusCode, body)
+		}
+
+		resp, _ := get(t, graphqlResponse, url.Values{"query": {"{ __typename }"}, "variables": {`{"some":"value"}`}})
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	})
+
+	t.Run("SHOULD use 400 status code when {variables} is not a map", func(t *testing.T) {
+		for _, body := range []string{`{"query":"{ __typename }","variables":"str"}`, `{"query":"{ __typename }","variables":["arr"]}`} {
+			resp, _ := post(t, graphqlResponse, body)
+			assert.Equal(t, http.StatusBadRequest, resp.StatusCode, body)
+		}
+	})
+
+	t.Run("MUST allow null and map {extensions} parameter", func(t *testing.T) {
+		for _, body := range []string{`{"query":"{ __typename }","extensions":null}`, `{"query":"{ __typename }","extensions":{"some":"value"}}`} {
+			resp, _ := post(t, graphqlResponse, body)
+			assert.Equal(t, http.StatusOK, resp.StatusCode, body)
+		}
+	})
+
+	t.Run("SHOULD use 400 status code when {extensions} is not a map", func(t *testing.T) {
+		for _, body := range []string{`{"query":"{ __typename }","extensions":"str"}`, `{"query":"{ __typename }","extensions":["arr"]}`} {
+			resp, _ := post(t, graphqlResponse, body)
+			assert.Equal(t, http.StatusBadRequest, resp.StatusCode, body)
+		}
+	})
+
+	t.Run("SHOULD use 400 status code and no data on document parsing failure", func(t *testing.T) {
+		resp, body := post(t, graphqlResponse, `{"query":"{"}`)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		assert.NotContains(t, body, `"data"`)
+	})
+
+	t.Run("SHOULD use 400 status code and no data on document validation failure", func(t *testing.T) {
+		resp, body := post(t, graphqlResponse, `{"query":"{ 8f31403dfe404bccbb0e835f2629c6a7 }"}`)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		assert.NotContains(t, body, `"data"`)
+	})
+
+	t.Run("SHOULD use 200 status code on errors when accepting application/json", func(t *testing.T) {
+		for _, body := range []string{`{"query":"{"}`, `{"query":"{ 8f31403dfe404bccbb0e835f2629c6a7 }"}`} {
+			resp, _ := post(t, json, body)
+			assert.Equal(t, http.StatusOK, resp.StatusCode, body)
+		}
+	})
+
+	t.Run("MUST NOT allow executing mutations on GET requests", func(t *testing.T) {
+		resp, _ := get(t, graphqlResponse, url.Values{"query": {"mutation { __typename }"}})
+		assert.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+	})
+}
@@ -10,6 +10,7 @@ import (
 
 	"github.com/99designs/gqlgen/graphql"
 	fibergqlgen "github.com/NickTaporuk/fiber-gqlgen"
+	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/utils"
 )
 
@@ -23,6 +24,15 @@ type MultipartForm struct {
 	// as multipart/form-data in memory, with the remainder stored on disk in
 	// temporary files.
 	MaxMemory int64
+
+	// CSRFPrevention rejects requests that do not carry one of the PreflightHeaders, a browser sends
+	// multipart forms cross-site without a CORS preflight. Enable it when cookies or other ambient
+	// credentials authenticate requests.
+	CSRFPrevention bool
+
+	// PreflightHeaders lists the headers of which at least one must be set to a non-empty value when
+	// CSRFPrevention is enabled. Defaults to X-Apollo-Operation-Name and Apollo-Require-Preflight.
+	PreflightHeaders []string
 }
 
 var _ fibergqlgen.Transport = MultipartForm{}
@@ -40,7 +50,15 @@ func (f MultipartForm) Supports(c *fiber.Ctx) bool {
 	return c.Method() == "POST" && mediaType == "multipart/form-data"
 }
 
+func (f MultipartForm) Methods() []string {
+	return []string{fiber.MethodPost}
+}
+
 func (f MultipartForm) Do(c *fiber.Ctx, exec graphql.GraphExecutor) error {
+	if f.CSRFPrevention && !preflighted(c, f.PreflightHeaders) {
+		return csrfBlocked(c, f.PreflightHeaders)
+	}
+
 	c.Set("Content-Type", "application/json")
 
 	start := graphql.Now()
@@ -57,12 +75,13 @@ func (f MultipartForm) Do(c *fiber.Ctx, exec graphql.GraphExecutor) error {
 		return writeJsonError(c, "failed to parse multipart form")
 	}
 
-	var params graphql.RawParams
+	var operations rawParams
 
-	if err = c.App().Config().JSONDecoder(utils.UnsafeBytes(c.FormValue("operations")), &params); err != nil {
+	if err = c.App().Config().JSONDecoder(utils.UnsafeBytes(c.FormValue("operations")), &operations); err != nil {
 		c.Status(fiber.StatusUnprocessableEntity)
 		return writeJsonError(c, "operations form field could not be decoded")
 	}
+	params := operations.params()
 
 	uploadsMap := map[string][]string{}
 	if err = json.Unmarshal([]byte(c.FormValue("map")), &uploadsMap); err != nil {
@@ -170,13 +189,13 @@ func (f MultipartForm) Do(c *fiber.Ctx, exec graphql.GraphExecutor) error {
 		End:   graphql.Now(),
 	}
 
-	rc, gerr := exec.CreateOperationContext(c.Context(), &params)
+	rc, gerr := exec.CreateOperationContext(c.UserContext(), params)
 	if gerr != nil {
-		resp := exec.DispatchError(graphql.WithOperationContext(c.Context(), rc), gerr)
+		resp := exec.DispatchError(graphql.WithOperationContext(c.UserContext(), rc), gerr)
 		c.Status(statusFor(gerr))
 		return writeJson(c, resp)
 	}
-	responses, ctx := exec.DispatchOperation(c.Context(), rc)
+	responses, ctx := exec.DispatchOperation(c.UserContext(), rc)
 	return writeJson(c, responses(ctx))
 }
 
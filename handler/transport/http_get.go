@@ -1,9 +1,13 @@
 package transport
 
 import (
+	"errors"
+	"fmt"
+
 	"github.com/99designs/gqlgen/graphql"
 	"github.com/99designs/gqlgen/graphql/errcode"
 	fibergqlgen "github.com/NickTaporuk/fiber-gqlgen"
+	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/utils"
 	"github.com/vektah/gqlparser/v2/ast"
 	"github.com/vektah/gqlparser/v2/gqlerror"
@@ -11,7 +15,35 @@ import (
 
 // GET implements the GET side of the default HTTP transport
 // defined in https://github.com/APIs-guru/graphql-over-http#get
-type GET struct{}
+type GET struct {
+	// Strict applies the GraphQL over HTTP status codes to application/json responses too, instead of
+	// only to application/graphql-response+json ones.
+	Strict bool
+
+	// MaxQueryStringSize sets the maximum number of bytes of the url query string. Like the variables
+	// limits it only applies to this transport, the query string of GRAPHQL and SSE requests is bounded
+	// by the ReadBufferSize of the fiber app.
+	MaxQueryStringSize int
+
+	// MaxVariablesSize sets the maximum number of bytes of the variables parameter.
+	MaxVariablesSize int
+
+	// MaxVariablesDepth sets the maximum nesting of objects and lists in the variables, counting
+	// the variables object itself.
+	MaxVariablesDepth int
+
+	// MaxVariables sets the maximum number of variables.
+	MaxVariables int
+
+	// CSRFPrevention rejects requests a browser could send cross-site without a CORS preflight, those
+	// without a non-simple Content-Type or one of the PreflightHeaders. Enable it when cookies or
+	// other ambient credentials authenticate requests.
+	CSRFPrevention bool
+
+	// PreflightHeaders lists the headers of which at least one must be set to a non-empty value when
+	// CSRFPrevention is enabled. Defaults to X-Apollo-Operation-Name and Apollo-Require-Preflight.
+	PreflightHeaders []string
+}
 
 var _ fibergqlgen.Transport = GET{}
 
@@ -23,9 +55,64 @@ func (h GET) Supports(c *fiber.Ctx) bool {
 	return c.Method() == "GET"
 }
 
+func (h GET) Methods() []string {
+	return []string{fiber.MethodGet}
+}
+
 func (h GET) Do(c *fiber.Ctx, exec graphql.GraphExecutor) error {
-	c.Set("Content-Type", "application/json")
+	if h.CSRFPrevention && !preflighted(c, h.PreflightHeaders) {
+		return csrfBlocked(c, h.PreflightHeaders)
+	}
+
+	o, ok := negotiate(c, h.Strict, mediaTypeMultipartMixed)
+	if !ok {
+		return notAcceptable(c)
+	}
+	c.Set("Content-Type", o.contentType())
+
+	// requests over the limits are answered with 414 URI Too Long before anything is decoded
+	if size := len(c.Request().URI().QueryString()); h.MaxQueryStringSize != 0 && size > h.MaxQueryStringSize {
+		return tooLarge(c, o, fiber.StatusRequestURITooLong, fmt.Errorf("query string of %d bytes exceeds the maximum of %d", size, h.MaxQueryStringSize))
+	}
+	limits := variablesLimits{size: h.MaxVariablesSize, depth: h.MaxVariablesDepth, count: h.MaxVariables}
+	if err := limits.check(c.Request().URI().QueryArgs().Peek("variables")); err != nil {
+		return tooLarge(c, o, fiber.StatusRequestURITooLong, err)
+	}
+
+	raw, err := paramsFromQuery(c)
+	if err != nil {
+		c.Status(fiber.StatusBadRequest)
+		return o.writeRequestError(c, &graphql.Response{Errors: gqlerror.List{{Message: err.Error()}}})
+	}
+
+	rc, gerr := exec.CreateOperationContext(c.UserContext(), raw)
+	if gerr != nil {
+		c.Status(o.requestErrorStatus(gerr))
+		resp := exec.DispatchError(graphql.WithOperationContext(c.UserContext(), rc), gerr)
+		return o.writeRequestError(c, resp)
+	}
+	op := rc.Doc.Operations.ForName(rc.OperationName)
+	if op.Operation != ast.Query {
+		if o.spec() {
+			c.Set("Allow", "POST")
+			c.Status(fiber.StatusMethodNotAllowed)
+			return o.writeRequestError(c, &graphql.Response{Errors: gqlerror.List{{Message: "GET requests only allow query operations"}}})
+		}
+		c.Status(fiber.StatusNotAcceptable)
+		return writeJsonError(c, "GET requests only allow query operations")
+	}
+
+	if o.stream == mediaTypeMultipartMixed {
+		return writeMultipartMixed(c, exec, rc)
+	}
+
+	responses, ctx := exec.DispatchOperation(c.UserContext(), rc)
+	return writeJson(c, responses(ctx))
+}
 
+// paramsFromQuery reads the request parameters from the url query string as described in
+// https://github.com/APIs-guru/graphql-over-http#get
+func paramsFromQuery(c *fiber.Ctx) (*graphql.RawParams, error) {
 	raw := &graphql.RawParams{
 		Query:         c.Query("query"),
 		OperationName: c.Query("operationName"),
@@ -34,34 +121,21 @@ func (h GET) Do(c *fiber.Ctx, exec graphql.GraphExecutor) error {
 
 	if variables := c.Query("variables"); variables != "" {
 		if err := c.App().Config().JSONDecoder(utils.UnsafeBytes(variables), &raw.Variables); err != nil {
-			c.Status(fiber.StatusBadRequest)
-			return writeJsonError(c, "variables could not be decoded")
+			return nil, errors.New("variables could not be decoded")
 		}
 	}
 
 	if extensions := c.Query("extensions"); extensions != "" {
 		if err := c.App().Config().JSONDecoder(utils.UnsafeBytes(extensions), &raw.Extensions); err != nil {
-			c.Status(fiber.StatusBadRequest)
-			return writeJsonError(c, "extensions could not be decoded")
+			return nil, errors.New("extensions could not be decoded")
 		}
 	}
 
-	raw.ReadTime.End = graphql.Now()
+	setDocumentID(raw, c.Query("documentId"))
 
-	rc, err := exec.CreateOperationContext(c.Context(), raw)
-	if err != nil {
-		c.Status(statusFor(err))
-		resp := exec.DispatchError(graphql.WithOperationContext(c.Context(), rc), err)
-		return writeJson(c, resp)
-	}
-	op := rc.Doc.Operations.ForName(rc.OperationName)
-	if op.Operation != ast.Query {
-		c.Status(fiber.StatusNotAcceptable)
-		return writeJsonError(c, "GET requests only allow query operations")
-	}
+	raw.ReadTime.End = graphql.Now()
 
-	responses, ctx := exec.DispatchOperation(c.Context(), rc)
-	return writeJson(c, responses(ctx))
+	return raw, nil
 }
 
 func statusFor(errs gqlerror.List) int {
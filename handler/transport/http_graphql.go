@@ -0,0 +1,64 @@
+package transport
+
+import (
+	"mime"
+
+	"github.com/99designs/gqlgen/graphql"
+	fibergqlgen "github.com/NickTaporuk/fiber-gqlgen"
+	"github.com/gofiber/fiber/v2"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+// GRAPHQL implements the application/graphql side of the HTTP transport
+// see: https://graphql.org/learn/serving-over-http/#post-request
+// The body is the query document, operationName, variables and extensions are
+// read from the url query string the same way the GET transport reads them.
+type GRAPHQL struct {
+	// Strict applies the GraphQL over HTTP status codes to application/json responses too, instead of
+	// only to application/graphql-response+json ones.
+	Strict bool
+}
+
+var _ fibergqlgen.Transport = GRAPHQL{}
+
+func (h GRAPHQL) Supports(c *fiber.Ctx) bool {
+	if c.Get("Upgrade") != "" {
+		return false
+	}
+
+	mediaType, _, err := mime.ParseMediaType(c.Get("Content-Type"))
+	if err != nil {
+		return false
+	}
+
+	return c.Method() == "POST" && mediaType == "application/graphql"
+}
+
+func (h GRAPHQL) Methods() []string {
+	return []string{fiber.MethodPost}
+}
+
+func (h GRAPHQL) Do(c *fiber.Ctx, exec graphql.GraphExecutor) error {
+	o, ok := negotiate(c, h.Strict)
+	if !ok {
+		return notAcceptable(c)
+	}
+	c.Set("Content-Type", o.contentType())
+
+	params, err := paramsFromQuery(c)
+	if err != nil {
+		c.Status(fiber.StatusBadRequest)
+		return o.writeRequestError(c, &graphql.Response{Errors: gqlerror.List{{Message: err.Error()}}})
+	}
+	params.Query = string(c.Body())
+
+	rc, gerr := exec.CreateOperationContext(c.UserContext(), params)
+	if gerr != nil {
+		c.Status(o.requestErrorStatus(gerr))
+		resp := exec.DispatchError(graphql.WithOperationContext(c.UserContext(), rc), gerr)
+		return o.writeRequestError(c, resp)
+	}
+
+	responses, ctx := exec.DispatchOperation(c.UserContext(), rc)
+	return writeJson(c, responses(ctx))
+}
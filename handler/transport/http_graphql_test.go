@@ -0,0 +1,83 @@
+package transport_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/NickTaporuk/fiber-gqlgen/handler/testserver"
+	"github.com/NickTaporuk/fiber-gqlgen/handler/transport"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGRAPHQL(t *testing.T) {
+	h := testserver.New()
+	h.AddTransport(transport.GRAPHQL{})
+
+	app := fiber.New()
+	app.Post("/graphql", h.ServeGraphQL)
+
+	doGraphQL := func(t *testing.T, target, contentType, body string) (int, string) {
+		req := httptest.NewRequest("POST", target, strings.NewReader(body))
+		req.Header.Set("Content-Type", contentType)
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+
+		b, err := ioutil.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return resp.StatusCode, string(b)
+	}
+
+	t.Run("success", func(t *testing.T) {
+		code, body := doGraphQL(t, "/graphql", "application/graphql", `{ name }`)
+		assert.Equal(t, http.StatusOK, code)
+		assert.Equal(t, `{"data":{"name":"test"}}`, body)
+	})
+
+	t.Run("with charset", func(t *testing.T) {
+		code, body := doGraphQL(t, "/graphql", "application/graphql; charset=utf-8", `{ name }`)
+		assert.Equal(t, http.StatusOK, code)
+		assert.Equal(t, `{"data":{"name":"test"}}`, body)
+	})
+
+	t.Run("operation name and variables from the query string", func(t *testing.T) {
+		code, body := doGraphQL(t, `/graphql?operationName=B&variables={"id":1}`, "application/graphql", `query A { name } query B($id: Int!) { find(id: $id) }`)
+		assert.Equal(t, http.StatusOK, code)
+		assert.Equal(t, `{"data":{"name":"test"}}`, body)
+
+		code, body = doGraphQL(t, "/graphql?operationName=C", "application/graphql", `query A { name } query B { name }`)
+		assert.Equal(t, http.StatusUnprocessableEntity, code)
+		assert.Equal(t, `{"errors":[{"message":"operation C not found","extensions":{"code":"GRAPHQL_VALIDATION_FAILED"}}],"data":null}`, body)
+	})
+
+	t.Run("decode failure", func(t *testing.T) {
+		code, body := doGraphQL(t, "/graphql?variables=notjson", "application/graphql", `{ name }`)
+		assert.Equal(t, http.StatusBadRequest, code)
+		assert.Equal(t, `{"errors":[{"message":"variables could not be decoded"}],"data":null}`, body)
+
+		code, body = doGraphQL(t, "/graphql?extensions=notjson", "application/graphql", `{ name }`)
+		assert.Equal(t, http.StatusBadRequest, code)
+		assert.Equal(t, `{"errors":[{"message":"extensions could not be decoded"}],"data":null}`, body)
+	})
+
+	t.Run("parse failure", func(t *testing.T) {
+		code, body := doGraphQL(t, "/graphql", "application/graphql", `!`)
+		assert.Equal(t, http.StatusUnprocessableEntity, code)
+		assert.Equal(t, `{"errors":[{"message":"Unexpected !","locations":[{"line":1,"column":1}],"extensions":{"code":"GRAPHQL_PARSE_FAILED"}}],"data":null}`, body)
+	})
+
+	t.Run("validation failure", func(t *testing.T) {
+		code, body := doGraphQL(t, "/graphql", "application/graphql", `{ title }`)
+		assert.Equal(t, http.StatusUnprocessableEntity, code)
+		assert.Equal(t, `{"errors":[{"message":"Cannot query field \"title\" on type \"Query\".","locations":[{"line":1,"column":3}],"extensions":{"code":"GRAPHQL_VALIDATION_FAILED"}}],"data":null}`, body)
+	})
+
+	t.Run("other content types are not supported", func(t *testing.T) {
+		code, _ := doGraphQL(t, "/graphql", "application/json", `{"query":"{ name }"}`)
+		assert.Equal(t, http.StatusBadRequest, code)
+	})
+}
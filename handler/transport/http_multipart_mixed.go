@@ -0,0 +1,108 @@
+package transport
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/gofiber/fiber/v2"
+	"github.com/valyala/fasthttp"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+// Incremental delivery over multipart/mixed as described in
+// https://github.com/graphql/graphql-over-http/blob/main/rfcs/IncrementalDelivery.md
+const (
+	mediaTypeMultipartMixed   = "multipart/mixed"
+	multipartMixedContentType = `multipart/mixed; boundary="-"; deferSpec=20220824`
+	multipartMixedPartHeader  = "\r\n---\r\nContent-Type: application/json; charset=utf-8\r\n\r\n"
+	multipartMixedTerminator  = "\r\n-----\r\n"
+)
+
+type (
+	initialPayload struct {
+		Errors     gqlerror.List          `json:"errors,omitempty"`
+		Data       json.RawMessage        `json:"data"`
+		Extensions map[string]interface{} `json:"extensions,omitempty"`
+		HasNext    bool                   `json:"hasNext"`
+	}
+
+	subsequentPayload struct {
+		Incremental []incrementalPayload `json:"incremental,omitempty"`
+		HasNext     bool                 `json:"hasNext"`
+	}
+
+	incrementalPayload struct {
+		Errors     gqlerror.List          `json:"errors,omitempty"`
+		Data       json.RawMessage        `json:"data"`
+		Path       ast.Path               `json:"path"`
+		Extensions map[string]interface{} `json:"extensions,omitempty"`
+	}
+)
+
+// writeMultipartMixed streams every response of the operation as its own part. The first response
+// is sent as the initial payload, all following ones as incremental payloads applied at the root.
+// As the executor does not tell whether another response follows, every part is sent with hasNext
+// set and the stream is closed by a final part without data.
+func writeMultipartMixed(c *fiber.Ctx, exec graphql.GraphExecutor, rc *graphql.OperationContext) error {
+	ctx, cancel := context.WithCancel(c.UserContext())
+	stream := startStream(ctx, exec, rc)
+
+	c.Set("Content-Type", multipartMixedContentType)
+	c.Status(fiber.StatusOK)
+	applyResponse(c)
+
+	c.Context().SetBodyStreamWriter(fasthttp.StreamWriter(func(w *bufio.Writer) {
+		defer cancel()
+
+		send := func(part []byte) bool {
+			if _, err := w.WriteString(multipartMixedPartHeader); err != nil {
+				return false
+			}
+			if _, err := w.Write(part); err != nil {
+				return false
+			}
+			return w.Flush() == nil
+		}
+
+		initial := true
+		stream.stream(send, func(resp *graphql.Response) []byte {
+			var payload interface{}
+			if initial {
+				initial = false
+				payload = &initialPayload{
+					Errors:     resp.Errors,
+					Data:       resp.Data,
+					Extensions: resp.Extensions,
+					HasNext:    true,
+				}
+			} else {
+				payload = &subsequentPayload{
+					Incremental: []incrementalPayload{{
+						Errors:     resp.Errors,
+						Data:       resp.Data,
+						Path:       ast.Path{},
+						Extensions: resp.Extensions,
+					}},
+					HasNext: true,
+				}
+			}
+
+			b, err := json.Marshal(payload)
+			if err != nil {
+				panic(err)
+			}
+			return b
+		})
+
+		if ctx.Err() != nil || !send([]byte(`{"hasNext":false}`)) {
+			return
+		}
+		_, _ = w.WriteString(multipartMixedTerminator)
+		_ = w.Flush()
+	}))
+
+	return nil
+}
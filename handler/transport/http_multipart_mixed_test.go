@@ -0,0 +1,138 @@
+package transport_test
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/NickTaporuk/fiber-gqlgen/handler"
+	"github.com/NickTaporuk/fiber-gqlgen/handler/testserver"
+	"github.com/NickTaporuk/fiber-gqlgen/handler/transport"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vektah/gqlparser/v2"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+func TestMultipartMixed(t *testing.T) {
+	es := &graphql.ExecutableSchemaMock{
+		ExecFunc: func(ctx context.Context) graphql.ResponseHandler {
+			responses := []*graphql.Response{
+				{Data: []byte(`{"name":"test"}`)},
+				{Data: []byte(`{"deferred":"later"}`)},
+			}
+			return func(ctx context.Context) *graphql.Response {
+				if len(responses) == 0 {
+					return nil
+				}
+				resp := responses[0]
+				responses = responses[1:]
+				return resp
+			}
+		},
+		SchemaFunc: func() *ast.Schema {
+			return gqlparser.MustLoadSchema(&ast.Source{Input: `
+				type Query {
+					name: String!
+				}
+			`})
+		},
+	}
+	h := handler.New(es)
+	h.AddTransport(transport.GET{})
+	h.AddTransport(transport.POST{})
+
+	app := fiber.New()
+	app.All("/graphql", h.ServeGraphQL)
+
+	expected := "\r\n---\r\nContent-Type: application/json; charset=utf-8\r\n\r\n" +
+		`{"data":{"name":"test"},"hasNext":true}` +
+		"\r\n---\r\nContent-Type: application/json; charset=utf-8\r\n\r\n" +
+		`{"incremental":[{"data":{"deferred":"later"},"path":[]}],"hasNext":true}` +
+		"\r\n---\r\nContent-Type: application/json; charset=utf-8\r\n\r\n" +
+		`{"hasNext":false}` +
+		"\r\n-----\r\n"
+
+	t.Run("POST", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/graphql", strings.NewReader(`{"query":"{ name }"}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Accept", "multipart/mixed; deferSpec=20220824, application/json")
+
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, `multipart/mixed; boundary="-"; deferSpec=20220824`, resp.Header.Get("Content-Type"))
+
+		b, err := ioutil.ReadAll(resp.Body)
+		require.NoError(t, err)
+		assert.Equal(t, expected, string(b))
+	})
+
+	t.Run("GET", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/graphql?query={name}", nil)
+		req.Header.Set("Accept", "multipart/mixed")
+
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		b, err := ioutil.ReadAll(resp.Body)
+		require.NoError(t, err)
+		assert.Equal(t, expected, string(b))
+	})
+
+	t.Run("buffers without multipart/mixed", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/graphql", strings.NewReader(`{"query":"{ name }"}`))
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+
+		b, err := ioutil.ReadAll(resp.Body)
+		require.NoError(t, err)
+		assert.Equal(t, `{"data":{"name":"test"}}`, string(b))
+	})
+
+	t.Run("buffers when multipart/mixed is not preferred", func(t *testing.T) {
+		for _, accept := range []string{
+			"application/json, multipart/mixed;q=0",
+			"application/json, multipart/mixed;q=0.5",
+			"application/graphql-response+json, multipart/mixed",
+		} {
+			req := httptest.NewRequest("POST", "/graphql", strings.NewReader(`{"query":"{ name }"}`))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Accept", accept)
+
+			resp, err := app.Test(req)
+			require.NoError(t, err)
+			assert.NotContains(t, resp.Header.Get("Content-Type"), "multipart/mixed", accept)
+
+			b, err := ioutil.ReadAll(resp.Body)
+			require.NoError(t, err)
+			assert.Equal(t, `{"data":{"name":"test"}}`, string(b), accept)
+		}
+	})
+}
+
+func TestMultipartMixedRequestErrors(t *testing.T) {
+	h := testserver.New()
+	h.AddTransport(transport.POST{})
+
+	app := fiber.New()
+	app.All("/graphql", h.ServeGraphQL)
+
+	req := httptest.NewRequest("POST", "/graphql", strings.NewReader(`{"query":"!"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "multipart/mixed")
+
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.StatusCode)
+	assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+}
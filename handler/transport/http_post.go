@@ -1,16 +1,50 @@
 package transport
 
 import (
+	"fmt"
 	"mime"
 	"net/http"
 
 	"github.com/99designs/gqlgen/graphql"
 	fibergqlgen "github.com/NickTaporuk/fiber-gqlgen"
+	"github.com/gofiber/fiber/v2"
+	"github.com/vektah/gqlparser/v2/gqlerror"
 )
 
 // POST implements the POST side of the default HTTP transport
 // defined in https://github.com/APIs-guru/graphql-over-http#post
-type POST struct{}
+type POST struct {
+	// EnableBatching accepts a JSON array of operations as the request body, as sent by the
+	// Apollo and Relay batching links, and answers with an array of responses in the same order.
+	EnableBatching bool
+
+	// MaxBatchSize sets the maximum number of operations accepted in a single batch,
+	// by default 10.
+	MaxBatchSize int
+
+	// BatchConcurrency sets how many operations of a batch are executed at the same time,
+	// by default they are executed one after another.
+	BatchConcurrency int
+
+	// Strict applies the GraphQL over HTTP status codes to application/json responses too, instead of
+	// only to application/graphql-response+json ones.
+	Strict bool
+
+	// MaxBodySize sets the maximum number of bytes of the request body. Like the variables limits it only
+	// applies to this transport, the GRAPHQL, URLEncodedForm, MultipartForm and SSE transports are bounded
+	// by the BodyLimit of the fiber app.
+	MaxBodySize int
+
+	// MaxVariablesSize sets the maximum number of bytes of the variables of an operation.
+	MaxVariablesSize int
+
+	// MaxVariablesDepth sets the maximum nesting of objects and lists in the variables, counting
+	// the variables object itself.
+	MaxVariablesDepth int
+
+	// MaxVariables sets the maximum number of variables of an operation.
+	MaxVariables int
+}
 
 var _ fibergqlgen.Transport = POST{}
 
@@ -27,26 +61,56 @@ func (h POST) Supports(c *fiber.Ctx) bool {
 	return c.Method() == "POST" && mediaType == "application/json"
 }
 
+func (h POST) Methods() []string {
+	return []string{fiber.MethodPost}
+}
+
 func (h POST) Do(c *fiber.Ctx, exec graphql.GraphExecutor) error {
-	c.Set("Content-Type", "application/json")
+	o, ok := negotiate(c, h.Strict, mediaTypeMultipartMixed)
+	if !ok {
+		return notAcceptable(c)
+	}
+	c.Set("Content-Type", o.contentType())
+
+	// requests over the limits are answered with 413 Payload Too Large before anything is decoded
+	if size := len(c.Body()); h.MaxBodySize != 0 && size > h.MaxBodySize {
+		return tooLarge(c, o, fiber.StatusRequestEntityTooLarge, fmt.Errorf("request body of %d bytes exceeds the maximum of %d", size, h.MaxBodySize))
+	}
+	limits := variablesLimits{size: h.MaxVariablesSize, depth: h.MaxVariablesDepth, count: h.MaxVariables}
+	if err := limits.checkBody(c.Body()); err != nil {
+		return tooLarge(c, o, fiber.StatusRequestEntityTooLarge, err)
+	}
+
+	if h.EnableBatching && isBatch(c.Body()) {
+		return h.doBatch(c, exec, o)
+	}
 
-	var params *graphql.RawParams
+	var body *rawParams
 	start := graphql.Now()
-	if err := c.App().Config().JSONDecoder(c.Body(), &params); err != nil {
+	if err := c.App().Config().JSONDecoder(c.Body(), &body); err != nil {
+		c.Status(http.StatusBadRequest)
+		return o.writeRequestError(c, &graphql.Response{Errors: gqlerror.List{{Message: "json body could not be decoded: " + err.Error()}}})
+	}
+	if body == nil {
 		c.Status(http.StatusBadRequest)
-		return writeJsonErrorf(c, "json body could not be decoded: "+err.Error())
+		return o.writeRequestError(c, &graphql.Response{Errors: gqlerror.List{{Message: "json body could not be decoded: body must be an object"}}})
 	}
+	params := body.params()
 	params.ReadTime = graphql.TraceTiming{
 		Start: start,
 		End:   graphql.Now(),
 	}
 
-	rc, err := exec.CreateOperationContext(c.Context(), params)
+	rc, err := exec.CreateOperationContext(c.UserContext(), params)
 	if err != nil {
-		c.Status(statusFor(err))
-		resp := exec.DispatchError(graphql.WithOperationContext(c.Context(), rc), err)
-		return writeJson(c, resp)
+		c.Status(o.requestErrorStatus(err))
+		resp := exec.DispatchError(graphql.WithOperationContext(c.UserContext(), rc), err)
+		return o.writeRequestError(c, resp)
 	}
-	responses, ctx := exec.DispatchOperation(c.Context(), rc)
+	if o.stream == mediaTypeMultipartMixed {
+		return writeMultipartMixed(c, exec, rc)
+	}
+
+	responses, ctx := exec.DispatchOperation(c.UserContext(), rc)
 	return writeJson(c, responses(ctx))
 }
@@ -0,0 +1,120 @@
+package transport
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/gofiber/fiber/v2"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+// isBatch reports whether the body holds a JSON array of operations rather than a single one
+func isBatch(body []byte) bool {
+	body = bytes.TrimLeft(body, " \t\r\n")
+	return len(body) > 0 && body[0] == '['
+}
+
+func (h POST) doBatch(c *fiber.Ctx, exec graphql.GraphExecutor, o overHTTP) error {
+	var batch []*rawParams
+	start := graphql.Now()
+	if err := c.App().Config().JSONDecoder(c.Body(), &batch); err != nil {
+		c.Status(fiber.StatusBadRequest)
+		return o.writeRequestError(c, &graphql.Response{Errors: gqlerror.List{{Message: "json body could not be decoded: " + err.Error()}}})
+	}
+	end := graphql.Now()
+
+	if len(batch) == 0 {
+		c.Status(fiber.StatusBadRequest)
+		return o.writeRequestError(c, &graphql.Response{Errors: gqlerror.List{{Message: "batch must contain at least one operation"}}})
+	}
+	if len(batch) > h.maxBatchSize() {
+		c.Status(fiber.StatusBadRequest)
+		return o.writeRequestError(c, &graphql.Response{Errors: gqlerror.List{{Message: fmt.Sprintf("batch of %d operations exceeds the maximum of %d", len(batch), h.maxBatchSize())}}})
+	}
+
+	ctx := c.UserContext()
+	responses := make([]*graphql.Response, len(batch))
+	sem := make(chan struct{}, h.batchConcurrency())
+	var wg sync.WaitGroup
+	for i, body := range batch {
+		if body == nil {
+			responses[i] = &graphql.Response{Errors: gqlerror.List{{Message: "operation could not be decoded"}}}
+			continue
+		}
+		params := body.params()
+		params.ReadTime = graphql.TraceTiming{
+			Start: start,
+			End:   end,
+		}
+
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, params *graphql.RawParams) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			responses[i] = executeBatched(graphql.StartOperationTrace(ctx), exec, params)
+		}(i, params)
+	}
+	wg.Wait()
+
+	b, err := c.App().Config().JSONEncoder(responses)
+	if err != nil {
+		return err
+	}
+
+	applyResponse(c)
+	return c.Send(b)
+}
+
+// executeBatched runs a single operation of a batch. Panics are recovered here as they would
+// otherwise escape the goroutine the operation runs on.
+func executeBatched(ctx context.Context, exec graphql.GraphExecutor, params *graphql.RawParams) (resp *graphql.Response) {
+	var rc *graphql.OperationContext
+	defer func() {
+		if r := recover(); r != nil {
+			var err error
+			if rc != nil {
+				err = rc.Recover(ctx, r)
+			} else {
+				err = graphql.DefaultRecover(ctx, r)
+			}
+			var gqlerr *gqlerror.Error
+			if !errors.As(err, &gqlerr) {
+				// a RecoverFunc may return nil, which must not panic again outside of ServeGraphQL
+				gqlerr = &gqlerror.Error{Message: "internal system error"}
+				if err != nil {
+					gqlerr.Message = err.Error()
+				}
+			}
+			resp = &graphql.Response{Errors: gqlerror.List{gqlerr}}
+		}
+	}()
+
+	rc, errs := exec.CreateOperationContext(ctx, params)
+	if errs != nil {
+		return exec.DispatchError(graphql.WithOperationContext(ctx, rc), errs)
+	}
+
+	responses, ctx := exec.DispatchOperation(ctx, rc)
+	return responses(ctx)
+}
+
+func (h POST) maxBatchSize() int {
+	if h.MaxBatchSize == 0 {
+		return 10
+	}
+	return h.MaxBatchSize
+}
+
+func (h POST) batchConcurrency() int {
+	if h.BatchConcurrency < 1 {
+		return 1
+	}
+	return h.BatchConcurrency
+}
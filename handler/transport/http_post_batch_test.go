@@ -0,0 +1,113 @@
+package transport_test
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/NickTaporuk/fiber-gqlgen/handler/testserver"
+	"github.com/NickTaporuk/fiber-gqlgen/handler/transport"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPOSTBatching(t *testing.T) {
+	doBatch := func(t *testing.T, post transport.POST, body string) (int, string) {
+		h := testserver.New()
+		h.AddTransport(post)
+
+		app := fiber.New()
+		app.Post("/graphql", h.ServeGraphQL)
+
+		req := httptest.NewRequest("POST", "/graphql", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+
+		b, err := ioutil.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return resp.StatusCode, string(b)
+	}
+
+	t.Run("responses are returned in order", func(t *testing.T) {
+		code, body := doBatch(t, transport.POST{EnableBatching: true}, `[{"query":"{ name }"},{"query":"!"},{"query":"mutation { name }"}]`)
+		assert.Equal(t, http.StatusOK, code)
+		assert.Equal(t, `[{"data":{"name":"test"}},`+
+			`{"errors":[{"message":"Unexpected !","locations":[{"line":1,"column":1}],"extensions":{"code":"GRAPHQL_PARSE_FAILED"}}],"data":null},`+
+			`{"errors":[{"message":"mutations are not supported"}],"data":null}]`, body)
+	})
+
+	t.Run("in parallel", func(t *testing.T) {
+		code, body := doBatch(t, transport.POST{EnableBatching: true, BatchConcurrency: 3}, `[{"query":"{ name }"},{"query":"{ name }"},{"query":"{ name }"}]`)
+		assert.Equal(t, http.StatusOK, code)
+		assert.Equal(t, `[{"data":{"name":"test"}},{"data":{"name":"test"}},{"data":{"name":"test"}}]`, body)
+	})
+
+	t.Run("too many operations", func(t *testing.T) {
+		code, body := doBatch(t, transport.POST{EnableBatching: true, MaxBatchSize: 1}, `[{"query":"{ name }"},{"query":"{ name }"}]`)
+		assert.Equal(t, http.StatusBadRequest, code)
+		assert.Equal(t, `{"errors":[{"message":"batch of 2 operations exceeds the maximum of 1"}],"data":null}`, body)
+	})
+
+	t.Run("empty batch", func(t *testing.T) {
+		code, body := doBatch(t, transport.POST{EnableBatching: true}, ` []`)
+		assert.Equal(t, http.StatusBadRequest, code)
+		assert.Equal(t, `{"errors":[{"message":"batch must contain at least one operation"}],"data":null}`, body)
+	})
+
+	t.Run("decode failure", func(t *testing.T) {
+		code, body := doBatch(t, transport.POST{EnableBatching: true}, `[{"query":1}]`)
+		assert.Equal(t, http.StatusBadRequest, code)
+		assert.Equal(t, `{"errors":[{"message":"json body could not be decoded: json: cannot unmarshal number into Go struct field RawParams.Query of type string"}],"data":null}`, body)
+	})
+
+	t.Run("request errors follow the spec", func(t *testing.T) {
+		h := testserver.New()
+		h.AddTransport(transport.POST{EnableBatching: true})
+
+		app := fiber.New()
+		app.Post("/graphql", h.ServeGraphQL)
+
+		req := httptest.NewRequest("POST", "/graphql", strings.NewReader(`[]`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Accept", "application/graphql-response+json")
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+
+		b, err := ioutil.ReadAll(resp.Body)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		assert.Equal(t, `{"errors":[{"message":"batch must contain at least one operation"}]}`, string(b))
+	})
+
+	t.Run("recover func returning nil", func(t *testing.T) {
+		h := testserver.New()
+		h.AddTransport(transport.POST{EnableBatching: true})
+		h.SetRecoverFunc(func(ctx context.Context, err interface{}) error { return nil })
+		h.AroundResponses(func(ctx context.Context, next graphql.ResponseHandler) *graphql.Response {
+			panic("boom")
+		})
+
+		app := fiber.New()
+		app.Post("/graphql", h.ServeGraphQL)
+
+		req := httptest.NewRequest("POST", "/graphql", strings.NewReader(`[{"query":"{ name }"}]`))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+
+		b, err := ioutil.ReadAll(resp.Body)
+		require.NoError(t, err)
+		assert.Equal(t, `[{"errors":[{"message":"internal system error"}],"data":null}]`, string(b))
+	})
+
+	t.Run("disabled by default", func(t *testing.T) {
+		code, _ := doBatch(t, transport.POST{}, `[{"query":"{ name }"}]`)
+		assert.Equal(t, http.StatusBadRequest, code)
+	})
+}
@@ -0,0 +1,95 @@
+package transport
+
+import (
+	"mime"
+
+	"github.com/99designs/gqlgen/graphql"
+	fibergqlgen "github.com/NickTaporuk/fiber-gqlgen"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+// URLEncodedForm implements the application/x-www-form-urlencoded side of the HTTP transport, the
+// query, operationName, variables and extensions fields are read from the form body.
+//
+// Browsers send such forms cross-site without a CORS preflight, so requests must carry one of
+// PreflightHeaders unless DisableCSRFPrevention is set.
+type URLEncodedForm struct {
+	// Strict applies the GraphQL over HTTP status codes to application/json responses too, instead of
+	// only to application/graphql-response+json ones.
+	Strict bool
+
+	// PreflightHeaders lists the headers of which at least one must be set to a non-empty value.
+	// Defaults to X-Apollo-Operation-Name and Apollo-Require-Preflight.
+	PreflightHeaders []string
+
+	// DisableCSRFPrevention accepts requests without any of the PreflightHeaders. Only use it when
+	// cookies or other ambient credentials are not used to authenticate requests.
+	DisableCSRFPrevention bool
+}
+
+var _ fibergqlgen.Transport = URLEncodedForm{}
+
+func (h URLEncodedForm) Supports(c *fiber.Ctx) bool {
+	if c.Get("Upgrade") != "" {
+		return false
+	}
+
+	mediaType, _, err := mime.ParseMediaType(c.Get("Content-Type"))
+	if err != nil {
+		return false
+	}
+
+	return c.Method() == "POST" && mediaType == "application/x-www-form-urlencoded"
+}
+
+func (h URLEncodedForm) Methods() []string {
+	return []string{fiber.MethodPost}
+}
+
+func (h URLEncodedForm) Do(c *fiber.Ctx, exec graphql.GraphExecutor) error {
+	if !h.DisableCSRFPrevention && !preflighted(c, h.PreflightHeaders) {
+		return csrfBlocked(c, h.PreflightHeaders)
+	}
+
+	o, ok := negotiate(c, h.Strict)
+	if !ok {
+		return notAcceptable(c)
+	}
+	c.Set("Content-Type", o.contentType())
+
+	params := &graphql.RawParams{
+		Query:         c.FormValue("query"),
+		OperationName: c.FormValue("operationName"),
+	}
+	params.ReadTime.Start = graphql.Now()
+
+	if variables := c.FormValue("variables"); variables != "" {
+		if err := c.App().Config().JSONDecoder(utils.UnsafeBytes(variables), &params.Variables); err != nil {
+			c.Status(fiber.StatusBadRequest)
+			return o.writeRequestError(c, &graphql.Response{Errors: gqlerror.List{{Message: "variables could not be decoded"}}})
+		}
+	}
+
+	if extensions := c.FormValue("extensions"); extensions != "" {
+		if err := c.App().Config().JSONDecoder(utils.UnsafeBytes(extensions), &params.Extensions); err != nil {
+			c.Status(fiber.StatusBadRequest)
+			return o.writeRequestError(c, &graphql.Response{Errors: gqlerror.List{{Message: "extensions could not be decoded"}}})
+		}
+	}
+
+	setDocumentID(params, c.FormValue("documentId"))
+
+	params.ReadTime.End = graphql.Now()
+
+	rc, gerr := exec.CreateOperationContext(c.UserContext(), params)
+	if gerr != nil {
+		c.Status(o.requestErrorStatus(gerr))
+		resp := exec.DispatchError(graphql.WithOperationContext(c.UserContext(), rc), gerr)
+		return o.writeRequestError(c, resp)
+	}
+
+	responses, ctx := exec.DispatchOperation(c.UserContext(), rc)
+	return writeJson(c, responses(ctx))
+}
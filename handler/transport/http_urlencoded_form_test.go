@@ -0,0 +1,85 @@
+package transport_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/NickTaporuk/fiber-gqlgen/handler/testserver"
+	"github.com/NickTaporuk/fiber-gqlgen/handler/transport"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestURLEncodedForm(t *testing.T) {
+	doForm := func(t *testing.T, form transport.URLEncodedForm, values url.Values, headers map[string]string) (int, string) {
+		h := testserver.New()
+		h.AddTransport(form)
+
+		app := fiber.New()
+		app.Post("/graphql", h.ServeGraphQL)
+
+		req := httptest.NewRequest("POST", "/graphql", strings.NewReader(values.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+
+		b, err := ioutil.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return resp.StatusCode, string(b)
+	}
+	preflight := map[string]string{"Apollo-Require-Preflight": "true"}
+
+	t.Run("success", func(t *testing.T) {
+		code, body := doForm(t, transport.URLEncodedForm{}, url.Values{
+			"query":         {"query A { name } query B { name }"},
+			"operationName": {"B"},
+			"variables":     {`{"id":1}`},
+		}, preflight)
+		assert.Equal(t, http.StatusOK, code)
+		assert.Equal(t, `{"data":{"name":"test"}}`, body)
+	})
+
+	t.Run("decode failure", func(t *testing.T) {
+		code, body := doForm(t, transport.URLEncodedForm{}, url.Values{"query": {"{ name }"}, "variables": {"notjson"}}, preflight)
+		assert.Equal(t, http.StatusBadRequest, code)
+		assert.Equal(t, `{"errors":[{"message":"variables could not be decoded"}],"data":null}`, body)
+
+		code, body = doForm(t, transport.URLEncodedForm{}, url.Values{"query": {"{ name }"}, "extensions": {"notjson"}}, preflight)
+		assert.Equal(t, http.StatusBadRequest, code)
+		assert.Equal(t, `{"errors":[{"message":"extensions could not be decoded"}],"data":null}`, body)
+	})
+
+	t.Run("parse failure", func(t *testing.T) {
+		code, body := doForm(t, transport.URLEncodedForm{}, url.Values{"query": {"!"}}, preflight)
+		assert.Equal(t, http.StatusUnprocessableEntity, code)
+		assert.Equal(t, `{"errors":[{"message":"Unexpected !","locations":[{"line":1,"column":1}],"extensions":{"code":"GRAPHQL_PARSE_FAILED"}}],"data":null}`, body)
+	})
+
+	t.Run("csrf prevention", func(t *testing.T) {
+		values := url.Values{"query": {"{ name }"}}
+
+		code, body := doForm(t, transport.URLEncodedForm{}, values, nil)
+		assert.Equal(t, http.StatusBadRequest, code)
+		assert.Equal(t, `{"errors":[{"message":"this operation has been blocked as a potential Cross-Site Request Forgery (CSRF), please provide a non-empty value for one of the following headers: X-Apollo-Operation-Name, Apollo-Require-Preflight"}],"data":null}`, body)
+
+		code, _ = doForm(t, transport.URLEncodedForm{}, values, map[string]string{"X-Apollo-Operation-Name": "A"})
+		assert.Equal(t, http.StatusOK, code)
+
+		code, _ = doForm(t, transport.URLEncodedForm{PreflightHeaders: []string{"X-Requested-With"}}, values, preflight)
+		assert.Equal(t, http.StatusBadRequest, code)
+
+		code, _ = doForm(t, transport.URLEncodedForm{PreflightHeaders: []string{"X-Requested-With"}}, values, map[string]string{"X-Requested-With": "XMLHttpRequest"})
+		assert.Equal(t, http.StatusOK, code)
+
+		code, _ = doForm(t, transport.URLEncodedForm{DisableCSRFPrevention: true}, values, nil)
+		assert.Equal(t, http.StatusOK, code)
+	})
+}
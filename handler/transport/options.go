@@ -1,29 +1,212 @@
 package transport
 
 import (
+	"errors"
+	"strconv"
+	"strings"
+
 	"github.com/99designs/gqlgen/graphql"
 	fibergqlgen "github.com/NickTaporuk/fiber-gqlgen"
+	"github.com/gofiber/fiber/v2"
 )
 
 // Options responds to http OPTIONS and HEAD requests
-type Options struct{}
+//
+// The Allow header lists the methods of the transports added to the server, HEAD requests are answered
+// like GET requests without the body. When AllowOrigins is set, CORS preflight requests are answered too,
+// the responses of the other transports still need their Access-Control-Allow-Origin header, eg from the
+// cors middleware of fiber.
+type Options struct {
+	// AllowOrigins lists the origins allowed to send cross-origin requests, "*" allows any origin. CORS
+	// preflight requests are not answered when it is empty.
+	AllowOrigins []string
+
+	// AllowHeaders lists the request headers allowed in cross-origin requests. When empty the headers
+	// asked for by the preflight request are allowed.
+	AllowHeaders []string
 
-var _ fibergqlgen.Transport = Options{}
+	// AllowCredentials allows cross-origin requests to include cookies and other credentials. It can not
+	// be combined with the "*" origin, the origins sending credentials have to be listed.
+	AllowCredentials bool
+
+	// MaxAge sets for how many seconds the result of a preflight request can be cached, zero leaves it
+	// to the browser.
+	MaxAge int
+}
+
+var _ interface {
+	fibergqlgen.Transport
+	fibergqlgen.TransportMethods
+	fibergqlgen.TransportValidator
+} = Options{}
+
+// defaultAllow is the Allow header when the transports of the server are unknown
+const defaultAllow = "OPTIONS, GET, POST"
+
+// methodOrder is the order of the methods in the Allow header
+var methodOrder = []string{
+	fiber.MethodOptions,
+	fiber.MethodGet,
+	fiber.MethodHead,
+	fiber.MethodPost,
+	fiber.MethodPut,
+	fiber.MethodPatch,
+	fiber.MethodDelete,
+}
 
 func (o Options) Supports(c *fiber.Ctx) bool {
 	method := c.Method()
 	return method == "HEAD" || method == "OPTIONS"
 }
 
+func (o Options) Methods() []string {
+	return []string{fiber.MethodOptions, fiber.MethodHead}
+}
+
+// Validate rejects credentials allowed from any origin, that would let every site send requests with
+// the cookies of the user
+func (o Options) Validate() error {
+	if o.AllowCredentials && contains(o.AllowOrigins, "*") {
+		return errors.New("transport: Options can not allow credentials from the \"*\" origin, list the allowed origins instead")
+	}
+	return nil
+}
+
 func (o Options) Do(c *fiber.Ctx, exec graphql.GraphExecutor) error {
+	transports := fibergqlgen.TransportsFromContext(c.UserContext())
+	allow := allowedMethods(transports)
 
 	switch c.Method() {
 	case fiber.MethodOptions:
-		c.Set("Allow", "OPTIONS, GET, POST")
+		c.Set("Allow", allowHeader(allow))
+		if c.Get("Origin") != "" && c.Get("Access-Control-Request-Method") != "" {
+			return o.preflight(c, allow)
+		}
 		c.Status(fiber.StatusOK)
 	case fiber.MethodHead:
+		return head(c, exec, transports, allow)
+	}
+
+	return nil
+}
+
+// preflight answers a CORS preflight request, a request from an origin that is not allowed or for a
+// method no transport serves is answered without the CORS headers and rejected by the browser
+func (o Options) preflight(c *fiber.Ctx, allow []string) error {
+	c.Status(fiber.StatusNoContent)
+	c.Vary("Origin")
+
+	origin := o.allowOrigin(c.Get("Origin"))
+	if origin == "" || !allowMethod(allow, c.Get("Access-Control-Request-Method")) {
+		return nil
+	}
+
+	c.Set("Access-Control-Allow-Origin", origin)
+	c.Set("Access-Control-Allow-Methods", allowHeader(allow))
+	if len(o.AllowHeaders) > 0 {
+		c.Set("Access-Control-Allow-Headers", strings.Join(o.AllowHeaders, ", "))
+	} else if headers := c.Get("Access-Control-Request-Headers"); headers != "" {
+		c.Vary("Access-Control-Request-Headers")
+		c.Set("Access-Control-Allow-Headers", headers)
+	}
+	if o.AllowCredentials {
+		c.Set("Access-Control-Allow-Credentials", "true")
+	}
+	if o.MaxAge > 0 {
+		c.Set("Access-Control-Max-Age", strconv.Itoa(o.MaxAge))
+	}
+
+	return nil
+}
+
+// allowOrigin returns the value of the Access-Control-Allow-Origin header for origin, empty when it is not
+// allowed. The "*" wildcard never allows credentials, Validate rejects that configuration.
+func (o Options) allowOrigin(origin string) string {
+	for _, allowed := range o.AllowOrigins {
+		switch {
+		case allowed == "*" && o.AllowCredentials:
+			return ""
+		case allowed == "*":
+			return "*"
+		case strings.EqualFold(allowed, origin):
+			return origin
+		}
+	}
+	return ""
+}
+
+// allowMethod reports whether the method asked for by a preflight request is in allow, or in defaultAllow
+// when the transports of the server are unknown
+func allowMethod(allow []string, method string) bool {
+	if len(allow) == 0 {
+		allow = strings.Split(defaultAllow, ", ")
+	}
+	return contains(allow, method)
+}
+
+// head answers a HEAD request with the response of the transport serving the same GET request, fasthttp
+// drops the body and keeps the headers, Content-Length included
+func head(c *fiber.Ctx, exec graphql.GraphExecutor, transports []fibergqlgen.Transport, allow []string) error {
+	if !contains(allow, fiber.MethodHead) {
+		c.Set("Allow", allowHeader(allow))
 		c.Status(fiber.StatusMethodNotAllowed)
+		return nil
+	}
+
+	c.Method(fiber.MethodGet)
+	defer c.Method(fiber.MethodHead)
+
+	for _, t := range transports {
+		if t.Supports(c) {
+			return t.Do(c, exec)
+		}
 	}
 
+	c.Set("Allow", allowHeader(allow))
+	c.Status(fiber.StatusMethodNotAllowed)
 	return nil
 }
+
+// allowedMethods returns the methods of transports in methodOrder, HEAD is only allowed along with GET.
+// Transports that do not implement fibergqlgen.TransportMethods are ignored.
+func allowedMethods(transports []fibergqlgen.Transport) []string {
+	if len(transports) == 0 {
+		return nil
+	}
+
+	seen := map[string]bool{}
+	var extra []string
+	for _, t := range transports {
+		tm, ok := t.(fibergqlgen.TransportMethods)
+		if !ok {
+			continue
+		}
+		for _, method := range tm.Methods() {
+			if seen[method] {
+				continue
+			}
+			seen[method] = true
+			if !contains(methodOrder, method) {
+				extra = append(extra, method)
+			}
+		}
+	}
+	if !seen[fiber.MethodGet] {
+		delete(seen, fiber.MethodHead)
+	}
+
+	var methods []string
+	for _, method := range methodOrder {
+		if seen[method] {
+			methods = append(methods, method)
+		}
+	}
+	return append(methods, extra...)
+}
+
+func allowHeader(methods []string) string {
+	if len(methods) == 0 {
+		return defaultAllow
+	}
+	return strings.Join(methods, ", ")
+}
@@ -0,0 +1,130 @@
+package transport_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	fibergqlgen "github.com/NickTaporuk/fiber-gqlgen"
+	"github.com/NickTaporuk/fiber-gqlgen/handler/testserver"
+	"github.com/NickTaporuk/fiber-gqlgen/handler/transport"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOptionsTransports(t *testing.T) {
+	newApp := func(options transport.Options, transports ...fibergqlgen.Transport) *fiber.App {
+		h := testserver.New()
+		h.AddTransport(options)
+		for _, tr := range transports {
+			h.AddTransport(tr)
+		}
+
+		app := fiber.New()
+		app.All("/graphql", h.ServeGraphQL)
+		return app
+	}
+
+	do := func(t *testing.T, app *fiber.App, method, target string, headers map[string]string) (*http.Response, string) {
+		req := httptest.NewRequest(method, target, nil)
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		b, err := ioutil.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return resp, string(b)
+	}
+
+	t.Run("allow reflects the transports", func(t *testing.T) {
+		resp, _ := do(t, newApp(transport.Options{}, transport.POST{}, transport.GET{}), "OPTIONS", "/graphql", nil)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "OPTIONS, GET, HEAD, POST", resp.Header.Get("Allow"))
+
+		resp, _ = do(t, newApp(transport.Options{}, transport.POST{}), "OPTIONS", "/graphql", nil)
+		assert.Equal(t, "OPTIONS, POST", resp.Header.Get("Allow"))
+
+		resp, _ = do(t, newApp(transport.Options{}, transport.SSE{Streams: transport.NewSSEStreams()}), "OPTIONS", "/graphql", nil)
+		assert.Equal(t, "OPTIONS, GET, HEAD, POST, PUT, DELETE", resp.Header.Get("Allow"))
+	})
+
+	t.Run("head mirrors get", func(t *testing.T) {
+		app := newApp(transport.Options{}, transport.GET{})
+
+		get, getBody := do(t, app, "GET", "/graphql?query={name}", nil)
+		head, headBody := do(t, app, "HEAD", "/graphql?query={name}", nil)
+		assert.Equal(t, http.StatusOK, head.StatusCode)
+		assert.Equal(t, get.Header.Get("Content-Type"), head.Header.Get("Content-Type"))
+		assert.Equal(t, get.ContentLength, head.ContentLength)
+		assert.Equal(t, `{"data":{"name":"test"}}`, getBody)
+		assert.Empty(t, headBody)
+	})
+
+	t.Run("head without get", func(t *testing.T) {
+		resp, _ := do(t, newApp(transport.Options{}, transport.POST{}), "HEAD", "/graphql", nil)
+		assert.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+		assert.Equal(t, "OPTIONS, POST", resp.Header.Get("Allow"))
+	})
+
+	preflight := map[string]string{
+		"Origin":                         "https://example.com",
+		"Access-Control-Request-Method":  "POST",
+		"Access-Control-Request-Headers": "Content-Type, Authorization",
+	}
+
+	t.Run("cors preflight", func(t *testing.T) {
+		app := newApp(transport.Options{
+			AllowOrigins:     []string{"https://example.com"},
+			AllowCredentials: true,
+			MaxAge:           600,
+		}, transport.GET{}, transport.POST{})
+
+		resp, _ := do(t, app, "OPTIONS", "/graphql", preflight)
+		assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+		assert.Equal(t, "https://example.com", resp.Header.Get("Access-Control-Allow-Origin"))
+		assert.Equal(t, "OPTIONS, GET, HEAD, POST", resp.Header.Get("Access-Control-Allow-Methods"))
+		assert.Equal(t, "Content-Type, Authorization", resp.Header.Get("Access-Control-Allow-Headers"))
+		assert.Equal(t, "true", resp.Header.Get("Access-Control-Allow-Credentials"))
+		assert.Equal(t, "600", resp.Header.Get("Access-Control-Max-Age"))
+	})
+
+	t.Run("cors preflight with configured headers", func(t *testing.T) {
+		app := newApp(transport.Options{AllowOrigins: []string{"*"}, AllowHeaders: []string{"Content-Type"}}, transport.POST{})
+
+		resp, _ := do(t, app, "OPTIONS", "/graphql", preflight)
+		assert.Equal(t, "*", resp.Header.Get("Access-Control-Allow-Origin"))
+		assert.Equal(t, "Content-Type", resp.Header.Get("Access-Control-Allow-Headers"))
+		assert.Empty(t, resp.Header.Get("Access-Control-Allow-Credentials"))
+		assert.Empty(t, resp.Header.Get("Access-Control-Max-Age"))
+	})
+
+	t.Run("cors preflight from another origin", func(t *testing.T) {
+		app := newApp(transport.Options{AllowOrigins: []string{"https://example.org"}}, transport.POST{})
+
+		resp, _ := do(t, app, "OPTIONS", "/graphql", preflight)
+		assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+		assert.Empty(t, resp.Header.Get("Access-Control-Allow-Origin"))
+		assert.Empty(t, resp.Header.Get("Access-Control-Allow-Methods"))
+	})
+
+	t.Run("cors preflight for a method not served", func(t *testing.T) {
+		app := newApp(transport.Options{AllowOrigins: []string{"https://example.com"}}, transport.POST{})
+
+		resp, _ := do(t, app, "OPTIONS", "/graphql", map[string]string{
+			"Origin":                        "https://example.com",
+			"Access-Control-Request-Method": "DELETE",
+		})
+		assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+		assert.Empty(t, resp.Header.Get("Access-Control-Allow-Origin"))
+		assert.Empty(t, resp.Header.Get("Access-Control-Allow-Methods"))
+	})
+
+	t.Run("credentials from any origin", func(t *testing.T) {
+		assert.PanicsWithError(t, `transport: Options can not allow credentials from the "*" origin, list the allowed origins instead`, func() {
+			newApp(transport.Options{AllowOrigins: []string{"*"}, AllowCredentials: true})
+		})
+	})
+}
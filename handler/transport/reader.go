@@ -23,3 +23,23 @@ func (r *bytesReader) Read(b []byte) (n int, err error) {
 	r.i += int64(n)
 	return
 }
+
+func (r *bytesReader) Seek(offset int64, whence int) (int64, error) {
+	var abs int64
+	switch whence {
+	case io.SeekStart:
+		abs = offset
+	case io.SeekCurrent:
+		abs = r.i + offset
+	case io.SeekEnd:
+		abs = int64(len(*r.s)) + offset
+	default:
+		return 0, errors.New("invalid whence")
+	}
+	if abs < 0 {
+		return 0, errors.New("negative position")
+	}
+	r.i = abs
+	r.prevRune = -1
+	return abs, nil
+}
@@ -0,0 +1,229 @@
+package transport
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/gofiber/fiber/v2"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+const errRequestTooLargeCode = "REQUEST_TOO_LARGE"
+
+// variablesLimits bounds the variables of a request. They are checked on the raw JSON, before it
+// is decoded, a zero limit is not enforced. Only the GET and POST transports check them, the bodies
+// of the other transports are only bounded by the BodyLimit of the fiber app.
+type variablesLimits struct {
+	size  int
+	depth int
+	count int
+}
+
+// check reports the first limit the raw variables exceed
+func (l variablesLimits) check(raw []byte) error {
+	if l.size != 0 && len(raw) > l.size {
+		return fmt.Errorf("variables of %d bytes exceed the maximum of %d", len(raw), l.size)
+	}
+	if l.depth == 0 && l.count == 0 {
+		return nil
+	}
+
+	depth, ok := jsonDepth(raw)
+	if !ok {
+		// invalid JSON is reported by the decoder
+		return nil
+	}
+	if l.depth != 0 && depth > l.depth {
+		return fmt.Errorf("variables exceed the maximum depth of %d", l.depth)
+	}
+	if l.count != 0 {
+		count := 0
+		jsonMembers(raw, func(key string, value []byte) bool {
+			count++
+			return true
+		})
+		if count > l.count {
+			return fmt.Errorf("%d variables exceed the maximum of %d", count, l.count)
+		}
+	}
+
+	return nil
+}
+
+// checkBody checks the variables of every operation of a raw POST body, which is either a single
+// operation or a batch of them
+func (l variablesLimits) checkBody(body []byte) error {
+	if l == (variablesLimits{}) {
+		return nil
+	}
+
+	check := func(operation []byte) error {
+		var err error
+		jsonMembers(operation, func(key string, value []byte) bool {
+			// encoding/json matches the keys of params case-insensitively
+			if strings.EqualFold(key, "variables") {
+				err = l.check(value)
+			}
+			return err == nil
+		})
+		return err
+	}
+
+	body = trimJSONSpace(body)
+	if len(body) == 0 || body[0] != '[' {
+		return check(body)
+	}
+
+	var err error
+	jsonElements(body, func(operation []byte) bool {
+		err = check(operation)
+		return err == nil
+	})
+	return err
+}
+
+// tooLarge answers a request that exceeds a size limit with status and a GraphQL error
+func tooLarge(c *fiber.Ctx, o overHTTP, status int, err error) error {
+	gqlErr := &gqlerror.Error{Message: err.Error(), Extensions: map[string]interface{}{"code": errRequestTooLargeCode}}
+	c.Status(status)
+	return o.writeRequestError(c, &graphql.Response{Errors: gqlerror.List{gqlErr}})
+}
+
+func trimJSONSpace(b []byte) []byte {
+	for len(b) > 0 && isJSONSpace(b[0]) {
+		b = b[1:]
+	}
+	for len(b) > 0 && isJSONSpace(b[len(b)-1]) {
+		b = b[:len(b)-1]
+	}
+	return b
+}
+
+func isJSONSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\r' || c == '\n'
+}
+
+// jsonValueEnd returns the offset right after the JSON value starting at b[i], along with its nesting
+// depth. It only checks the structure as far as needed to find the end of the value.
+func jsonValueEnd(b []byte, i int) (end int, depth int, ok bool) {
+	level := 0
+	for ; i < len(b); i++ {
+		switch c := b[i]; c {
+		case '"':
+			i++
+			for ; i < len(b) && b[i] != '"'; i++ {
+				if b[i] == '\\' {
+					i++
+				}
+			}
+			if i >= len(b) {
+				return 0, 0, false
+			}
+			if level == 0 {
+				return i + 1, depth, true
+			}
+		case '{', '[':
+			level++
+			if level > depth {
+				depth = level
+			}
+		case '}', ']':
+			level--
+			if level < 0 {
+				return 0, 0, false
+			}
+			if level == 0 {
+				return i + 1, depth, true
+			}
+		case ',', ':':
+			if level == 0 {
+				return 0, 0, false
+			}
+		default:
+			if level == 0 && !isJSONSpace(c) {
+				// a literal, it ends with the next delimiter
+				for i++; i < len(b) && !isJSONSpace(b[i]) && b[i] != ',' && b[i] != '}' && b[i] != ']'; i++ {
+				}
+				return i, 0, true
+			}
+		}
+	}
+	return 0, 0, false
+}
+
+// jsonDepth returns the nesting depth of a raw JSON value
+func jsonDepth(b []byte) (int, bool) {
+	_, depth, ok := jsonValueEnd(b, 0)
+	return depth, ok
+}
+
+// jsonMembers calls fn with the key and raw value of every member of a raw JSON object until fn returns false
+func jsonMembers(b []byte, fn func(key string, value []byte) bool) {
+	b = trimJSONSpace(b)
+	if len(b) < 2 || b[0] != '{' {
+		return
+	}
+
+	i := 1
+	for {
+		for i < len(b) && (isJSONSpace(b[i]) || b[i] == ',') {
+			i++
+		}
+		if i >= len(b) || b[i] != '"' {
+			return
+		}
+		keyEnd, _, ok := jsonValueEnd(b, i)
+		if !ok {
+			return
+		}
+		var key string
+		if err := json.Unmarshal(b[i:keyEnd], &key); err != nil {
+			return
+		}
+
+		i = keyEnd
+		for i < len(b) && isJSONSpace(b[i]) {
+			i++
+		}
+		if i >= len(b) || b[i] != ':' {
+			return
+		}
+		i++
+		for i < len(b) && isJSONSpace(b[i]) {
+			i++
+		}
+		valueEnd, _, ok := jsonValueEnd(b, i)
+		if !ok {
+			return
+		}
+		if !fn(key, b[i:valueEnd]) {
+			return
+		}
+		i = valueEnd
+	}
+}
+
+// jsonElements calls fn with every raw element of a raw JSON array until fn returns false
+func jsonElements(b []byte, fn func(element []byte) bool) {
+	b = trimJSONSpace(b)
+	if len(b) < 2 || b[0] != '[' {
+		return
+	}
+
+	i := 1
+	for {
+		for i < len(b) && (isJSONSpace(b[i]) || b[i] == ',') {
+			i++
+		}
+		if i >= len(b) || b[i] == ']' {
+			return
+		}
+		end, _, ok := jsonValueEnd(b, i)
+		if !ok || !fn(b[i:end]) {
+			return
+		}
+		i = end
+	}
+}
@@ -0,0 +1,103 @@
+package transport_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/NickTaporuk/fiber-gqlgen/handler/testserver"
+	"github.com/NickTaporuk/fiber-gqlgen/handler/transport"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestLimits(t *testing.T) {
+	h := testserver.New()
+	h.AddTransport(transport.GET{MaxQueryStringSize: 200, MaxVariablesSize: 40, MaxVariablesDepth: 2, MaxVariables: 2})
+	h.AddTransport(transport.POST{MaxBodySize: 200, MaxVariablesSize: 40, MaxVariablesDepth: 2, MaxVariables: 2, EnableBatching: true})
+
+	app := fiber.New()
+	app.All("/graphql", h.ServeGraphQL)
+
+	do := func(t *testing.T, req *http.Request) (int, string) {
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		b, err := ioutil.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return resp.StatusCode, string(b)
+	}
+	get := func(variables string) *http.Request {
+		return httptest.NewRequest("GET", "/graphql?query="+url.QueryEscape("{ name }")+"&variables="+url.QueryEscape(variables), nil)
+	}
+	post := func(body string) *http.Request {
+		req := httptest.NewRequest("POST", "/graphql", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		return req
+	}
+	tooLarge := func(msg string) string {
+		return `{"errors":[{"message":"` + msg + `","extensions":{"code":"REQUEST_TOO_LARGE"}}],"data":null}`
+	}
+
+	t.Run("within the limits", func(t *testing.T) {
+		code, body := do(t, get(`{"id":1,"b":[1]}`))
+		assert.Equal(t, http.StatusOK, code, body)
+
+		code, body = do(t, post(`{"query":"{ name }","variables":{"id":1,"b":[1]}}`))
+		assert.Equal(t, http.StatusOK, code, body)
+	})
+
+	t.Run("GET", func(t *testing.T) {
+		code, body := do(t, get(`{"id":1,"b":"`+strings.Repeat("a", 40)+`"}`))
+		assert.Equal(t, http.StatusRequestURITooLong, code)
+		assert.Equal(t, tooLarge("variables of 55 bytes exceed the maximum of 40"), body)
+
+		code, body = do(t, get(`{"id":[[[1]]]}`))
+		assert.Equal(t, http.StatusRequestURITooLong, code)
+		assert.Equal(t, tooLarge("variables exceed the maximum depth of 2"), body)
+
+		code, body = do(t, get(`{"a":1,"b":2,"c":3}`))
+		assert.Equal(t, http.StatusRequestURITooLong, code)
+		assert.Equal(t, tooLarge("3 variables exceed the maximum of 2"), body)
+
+		code, body = do(t, httptest.NewRequest("GET", "/graphql?query="+strings.Repeat("a", 200), nil))
+		assert.Equal(t, http.StatusRequestURITooLong, code)
+		assert.Equal(t, tooLarge("query string of 206 bytes exceeds the maximum of 200"), body)
+	})
+
+	t.Run("POST", func(t *testing.T) {
+		code, body := do(t, post(`{"query":"`+strings.Repeat(" ", 200)+`{ name }"}`))
+		assert.Equal(t, http.StatusRequestEntityTooLarge, code)
+		assert.Equal(t, tooLarge("request body of 220 bytes exceeds the maximum of 200"), body)
+
+		code, body = do(t, post(`{"query":"{ name }","variables":{"id":"`+strings.Repeat("a", 40)+`"}}`))
+		assert.Equal(t, http.StatusRequestEntityTooLarge, code)
+		assert.Equal(t, tooLarge("variables of 49 bytes exceed the maximum of 40"), body)
+
+		code, body = do(t, post(`{"query":"{ name }","variables":{"id":{"a":{"b":1}}}}`))
+		assert.Equal(t, http.StatusRequestEntityTooLarge, code)
+		assert.Equal(t, tooLarge("variables exceed the maximum depth of 2"), body)
+
+		code, body = do(t, post(`{"variables":{"a":1,"b":"}","c":3},"query":"{ name }"}`))
+		assert.Equal(t, http.StatusRequestEntityTooLarge, code)
+		assert.Equal(t, tooLarge("3 variables exceed the maximum of 2"), body)
+
+		code, body = do(t, post(`{"variables":{"a":1,"b":2,"c":3},"query":"{ name }"}`))
+		assert.Equal(t, http.StatusRequestEntityTooLarge, code, "escaped keys are matched too")
+		assert.Equal(t, tooLarge("3 variables exceed the maximum of 2"), body)
+
+		code, body = do(t, post(`{"query":"{ name }","Variables":{"a":1,"b":2,"c":3}}`))
+		assert.Equal(t, http.StatusRequestEntityTooLarge, code, "keys are matched case-insensitively like the decoder does")
+		assert.Equal(t, tooLarge("3 variables exceed the maximum of 2"), body)
+
+		code, body = do(t, post(`[{"query":"{ name }"},{"query":"{ name }","variables":{"a":1,"b":2,"c":3}}]`))
+		assert.Equal(t, http.StatusRequestEntityTooLarge, code, "every operation of a batch is checked")
+		assert.Equal(t, tooLarge("3 variables exceed the maximum of 2"), body)
+
+		code, _ = do(t, post(`{"query":"{ name }","variables":{"a":`))
+		assert.Equal(t, http.StatusBadRequest, code, "invalid JSON is left to the decoder")
+	})
+}
@@ -0,0 +1,477 @@
+package transport
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql"
+	fibergqlgen "github.com/NickTaporuk/fiber-gqlgen"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+	"github.com/valyala/fasthttp"
+)
+
+// sseTokenHeader carries the reservation token of the "single connection mode"
+const sseTokenHeader = "X-GraphQL-Event-Stream-Token"
+
+// SSE implements the graphql-sse protocol https://github.com/enisdenjo/graphql-sse/blob/master/PROTOCOL.md
+//
+// SSE has to be added before the POST and GET transports, both of them would otherwise pick up
+// the requests it is meant to stream.
+type SSE struct {
+	// KeepAliveInterval defines how often a comment is written to an idle stream. A failing write is
+	// how a client that went away is noticed, so it also bounds how long an abandoned
+	// subscription keeps running.
+	//
+	// Optional. Default: 15s
+	KeepAliveInterval time.Duration
+
+	// Streams holds the reservations of the "single connection mode". When nil only the
+	// "distinct connections mode" is served.
+	//
+	// Optional. Default: nil
+	Streams *SSEStreams
+}
+
+// SSEStreams keeps track of the event streams reserved in the "single connection mode". It has to be
+// shared by every request of a client, so create it once with NewSSEStreams and set its fields before
+// the first request.
+type SSEStreams struct {
+	// ReservationTimeout defines how long a reservation waits for its event stream to be opened, it is
+	// dropped along with the operations running on it afterwards.
+	//
+	// Optional. Default: 30s
+	ReservationTimeout time.Duration
+
+	// MaxStreams defines how many streams can be reserved at once, further reservations are answered
+	// with 503 Service Unavailable.
+	//
+	// Optional. Default: 10000
+	MaxStreams int
+
+	mu      sync.Mutex
+	streams map[string]*sseStream
+}
+
+type sseStream struct {
+	mu      sync.Mutex
+	open    bool
+	closed  bool
+	expires *time.Timer
+	events  chan []byte
+	active  map[string]context.CancelFunc
+}
+
+var _ fibergqlgen.Transport = SSE{}
+
+// NewSSEStreams creates an empty set of single connection mode reservations
+func NewSSEStreams() *SSEStreams {
+	return &SSEStreams{streams: map[string]*sseStream{}}
+}
+
+func (t SSE) Supports(c *fiber.Ctx) bool {
+	if c.Get("Upgrade") != "" {
+		return false
+	}
+
+	if t.Streams != nil {
+		switch c.Method() {
+		case fiber.MethodPut:
+			// reservation of a stream
+			return sseToken(c) == "" && len(c.Body()) == 0
+		case fiber.MethodDelete:
+			// stop of an operation
+			return sseToken(c) != "" && c.Query("operationId") != ""
+		case fiber.MethodGet, fiber.MethodPost:
+			if sseToken(c) != "" {
+				return true
+			}
+		}
+	}
+
+	if !strings.Contains(c.Get("Accept"), "text/event-stream") {
+		return false
+	}
+
+	switch c.Method() {
+	case fiber.MethodGet:
+		return true
+	case fiber.MethodPost:
+		mediaType, _, err := mime.ParseMediaType(c.Get("Content-Type"))
+		if err != nil {
+			return false
+		}
+		return mediaType == "application/json"
+	default:
+		return false
+	}
+}
+
+func (t SSE) Methods() []string {
+	if t.Streams != nil {
+		return []string{fiber.MethodGet, fiber.MethodPost, fiber.MethodPut, fiber.MethodDelete}
+	}
+	return []string{fiber.MethodGet, fiber.MethodPost}
+}
+
+func (t SSE) Do(c *fiber.Ctx, exec graphql.GraphExecutor) error {
+	if t.Streams == nil {
+		return t.distinct(c, exec)
+	}
+
+	switch {
+	case c.Method() == fiber.MethodPut:
+		return t.reserve(c)
+	case c.Method() == fiber.MethodDelete:
+		return t.stop(c)
+	case sseToken(c) == "":
+		return t.distinct(c, exec)
+	case c.Method() == fiber.MethodGet:
+		return t.listen(c)
+	default:
+		return t.execute(c, exec)
+	}
+}
+
+// distinct runs a single operation and streams its results on the response of the same request
+func (t SSE) distinct(c *fiber.Ctx, exec graphql.GraphExecutor) error {
+	c.Set("Content-Type", "application/json")
+
+	params, err := sseParams(c)
+	if err != nil {
+		c.Status(fiber.StatusBadRequest)
+		return writeJsonError(c, err.Error())
+	}
+
+	rc, gerr := exec.CreateOperationContext(c.UserContext(), params)
+	if gerr != nil {
+		c.Status(statusFor(gerr))
+		resp := exec.DispatchError(graphql.WithOperationContext(c.UserContext(), rc), gerr)
+		return writeJson(c, resp)
+	}
+
+	// The stream outlives the fasthttp request context, so the operation runs on the user context.
+	ctx, cancel := context.WithCancel(graphql.WithOperationContext(c.UserContext(), rc))
+	stream := startStream(ctx, exec, rc)
+	events := make(chan []byte)
+	go func() {
+		defer close(events)
+		stream.stream(func(event []byte) bool {
+			select {
+			case events <- event:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}, func(resp *graphql.Response) []byte {
+			return sseEvent("next", resp)
+		})
+		select {
+		case events <- sseEvent("complete", nil):
+		case <-ctx.Done():
+		}
+	}()
+
+	t.stream(c, cancel, events)
+
+	return nil
+}
+
+// reserve creates a new "single connection mode" stream and answers with its token, the stream is
+// dropped when it is not listened to within the ReservationTimeout
+func (t SSE) reserve(c *fiber.Ctx) error {
+	token := utils.UUIDv4()
+
+	t.Streams.mu.Lock()
+	if len(t.Streams.streams) >= t.Streams.maxStreams() {
+		t.Streams.mu.Unlock()
+		return SendErrorf(c, fiber.StatusServiceUnavailable, "too many streams")
+	}
+	t.Streams.streams[token] = &sseStream{
+		events: make(chan []byte),
+		active: map[string]context.CancelFunc{},
+		expires: time.AfterFunc(t.Streams.reservationTimeout(), func() {
+			t.Streams.expire(token)
+		}),
+	}
+	t.Streams.mu.Unlock()
+
+	c.Set("Content-Type", "text/plain; charset=utf-8")
+	c.Status(fiber.StatusCreated)
+
+	return c.SendString(token)
+}
+
+// listen opens the event stream of a reservation
+func (t SSE) listen(c *fiber.Ctx) error {
+	token := sseToken(c)
+	s := t.Streams.get(token)
+	if s == nil {
+		return SendErrorf(c, fiber.StatusNotFound, "stream not found")
+	}
+
+	s.mu.Lock()
+	if s.open {
+		s.mu.Unlock()
+		return SendErrorf(c, fiber.StatusConflict, "stream is already open")
+	}
+	if !s.expires.Stop() {
+		// the reservation expired in the meantime
+		s.mu.Unlock()
+		return SendErrorf(c, fiber.StatusNotFound, "stream not found")
+	}
+	s.open = true
+	s.mu.Unlock()
+
+	t.stream(c, func() {
+		t.Streams.close(token)
+	}, s.events)
+
+	return nil
+}
+
+// execute runs an operation whose results are sent on the stream of a reservation
+func (t SSE) execute(c *fiber.Ctx, exec graphql.GraphExecutor) error {
+	c.Set("Content-Type", "application/json")
+
+	s := t.Streams.get(sseToken(c))
+	if s == nil {
+		return SendErrorf(c, fiber.StatusNotFound, "stream not found")
+	}
+
+	params, err := sseParams(c)
+	if err != nil {
+		c.Status(fiber.StatusBadRequest)
+		return writeJsonError(c, err.Error())
+	}
+
+	id, _ := params.Extensions["operationId"].(string)
+	if id == "" {
+		c.Status(fiber.StatusBadRequest)
+		return writeJsonError(c, "operationId extension is required")
+	}
+
+	rc, gerr := exec.CreateOperationContext(c.UserContext(), params)
+	if gerr != nil {
+		c.Status(statusFor(gerr))
+		resp := exec.DispatchError(graphql.WithOperationContext(c.UserContext(), rc), gerr)
+		return writeJson(c, resp)
+	}
+
+	// the operation outlives this request, it ends with the stream, when the reservation expires or when
+	// the client stops it
+	ctx, cancel := context.WithCancel(graphql.WithOperationContext(detachedContext{parent: c.UserContext()}, rc))
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		cancel()
+		return SendErrorf(c, fiber.StatusNotFound, "stream not found")
+	}
+	if _, ok := s.active[id]; ok {
+		s.mu.Unlock()
+		cancel()
+		return SendErrorf(c, fiber.StatusConflict, "operation %s is already running", id)
+	}
+	s.active[id] = cancel
+	s.mu.Unlock()
+
+	go func() {
+		defer func() {
+			s.mu.Lock()
+			delete(s.active, id)
+			s.mu.Unlock()
+			cancel()
+		}()
+		send := func(event []byte) bool {
+			select {
+			case s.events <- event:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+		startStream(ctx, exec, rc).stream(send, func(resp *graphql.Response) []byte {
+			return sseEvent("next", &sseOperationPayload{ID: id, Payload: resp})
+		})
+		// operations stopped by the client are not completed
+		if ctx.Err() == nil {
+			send(sseEvent("complete", &sseOperationPayload{ID: id}))
+		}
+	}()
+
+	c.Status(fiber.StatusAccepted)
+
+	return nil
+}
+
+// stop cancels a running operation of a reservation
+func (t SSE) stop(c *fiber.Ctx) error {
+	s := t.Streams.get(sseToken(c))
+	if s == nil {
+		return SendErrorf(c, fiber.StatusNotFound, "stream not found")
+	}
+
+	s.mu.Lock()
+	cancel := s.active[c.Query("operationId")]
+	s.mu.Unlock()
+	if cancel != nil {
+		cancel()
+	}
+
+	c.Status(fiber.StatusOK)
+
+	return nil
+}
+
+// stream writes events to the client until the channel is closed or the client goes away, cancel is
+// always called once the stream ends.
+func (t SSE) stream(c *fiber.Ctx, cancel func(), events <-chan []byte) {
+	c.Set("Content-Type", "text/event-stream")
+	c.Set("Cache-Control", "no-cache")
+	c.Set("Connection", "keep-alive")
+	c.Status(fiber.StatusOK)
+	applyResponse(c)
+
+	keepAlive := t.keepAliveInterval()
+	c.Context().SetBodyStreamWriter(fasthttp.StreamWriter(func(w *bufio.Writer) {
+		defer cancel()
+
+		ticker := time.NewTicker(keepAlive)
+		defer ticker.Stop()
+
+		if _, err := w.WriteString(":\n\n"); err != nil || w.Flush() != nil {
+			return
+		}
+		for {
+			select {
+			case event, ok := <-events:
+				if !ok {
+					return
+				}
+				if _, err := w.Write(event); err != nil || w.Flush() != nil {
+					return
+				}
+			case <-ticker.C:
+				if _, err := w.WriteString(":\n\n"); err != nil || w.Flush() != nil {
+					return
+				}
+			}
+		}
+	}))
+}
+
+func (t SSE) keepAliveInterval() time.Duration {
+	if t.KeepAliveInterval == 0 {
+		return 15 * time.Second
+	}
+	return t.KeepAliveInterval
+}
+
+func (s *SSEStreams) get(token string) *sseStream {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.streams[token]
+}
+
+// close drops the stream of token and cancels its operations
+func (s *SSEStreams) close(token string) {
+	s.mu.Lock()
+	stream := s.streams[token]
+	delete(s.streams, token)
+	s.mu.Unlock()
+
+	if stream == nil {
+		return
+	}
+
+	stream.mu.Lock()
+	stream.closed = true
+	stream.expires.Stop()
+	for _, cancel := range stream.active {
+		cancel()
+	}
+	stream.mu.Unlock()
+}
+
+// expire drops the stream of token unless it is listened to
+func (s *SSEStreams) expire(token string) {
+	stream := s.get(token)
+	if stream == nil {
+		return
+	}
+
+	stream.mu.Lock()
+	open := stream.open
+	stream.mu.Unlock()
+	if !open {
+		s.close(token)
+	}
+}
+
+func (s *SSEStreams) reservationTimeout() time.Duration {
+	if s.ReservationTimeout == 0 {
+		return 30 * time.Second
+	}
+	return s.ReservationTimeout
+}
+
+func (s *SSEStreams) maxStreams() int {
+	if s.MaxStreams == 0 {
+		return 10000
+	}
+	return s.MaxStreams
+}
+
+type sseOperationPayload struct {
+	ID      string            `json:"id"`
+	Payload *graphql.Response `json:"payload,omitempty"`
+}
+
+func sseEvent(event string, data interface{}) []byte {
+	if data == nil {
+		return []byte(fmt.Sprintf("event: %s\ndata:\n\n", event))
+	}
+
+	b, err := json.Marshal(data)
+	if err != nil {
+		panic(err)
+	}
+
+	return []byte(fmt.Sprintf("event: %s\ndata: %s\n\n", event, b))
+}
+
+func sseParams(c *fiber.Ctx) (*graphql.RawParams, error) {
+	if c.Method() == fiber.MethodGet {
+		return paramsFromQuery(c)
+	}
+
+	var params *graphql.RawParams
+	start := graphql.Now()
+	if err := c.App().Config().JSONDecoder(c.Body(), &params); err != nil {
+		return nil, fmt.Errorf("json body could not be decoded: %s", err.Error())
+	}
+	if params == nil {
+		return nil, errors.New("json body could not be decoded")
+	}
+	params.ReadTime = graphql.TraceTiming{
+		Start: start,
+		End:   graphql.Now(),
+	}
+
+	return params, nil
+}
+
+func sseToken(c *fiber.Ctx) string {
+	if token := c.Get(sseTokenHeader); token != "" {
+		return token
+	}
+	return c.Query("token")
+}
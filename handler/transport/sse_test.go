@@ -0,0 +1,186 @@
+package transport_test
+
+import (
+	"bufio"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/NickTaporuk/fiber-gqlgen/handler/testserver"
+	"github.com/NickTaporuk/fiber-gqlgen/handler/transport"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSSE(t *testing.T) {
+	h := testserver.New()
+	h.AddTransport(transport.SSE{})
+	h.AddTransport(transport.POST{})
+
+	app := fiber.New()
+	app.All("/graphql", h.ServeGraphQL)
+
+	t.Run("streams responses", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/graphql", strings.NewReader(`{"query":"{ name }"}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Accept", "text/event-stream")
+
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+		b, err := ioutil.ReadAll(resp.Body)
+		require.NoError(t, err)
+		assert.Equal(t, ":\n\nevent: next\ndata: {\"data\":{\"name\":\"test\"}}\n\nevent: complete\ndata:\n\n", string(b))
+	})
+
+	t.Run("parse failure", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/graphql", strings.NewReader(`{"query":"!"}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Accept", "text/event-stream")
+
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusUnprocessableEntity, resp.StatusCode)
+		assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+	})
+
+	t.Run("is not used without text/event-stream", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/graphql", strings.NewReader(`{"query":"{ name }"}`))
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		b, err := ioutil.ReadAll(resp.Body)
+		require.NoError(t, err)
+		assert.Equal(t, `{"data":{"name":"test"}}`, string(b))
+	})
+}
+
+func TestSSESingleConnection(t *testing.T) {
+	h := testserver.New()
+	h.AddTransport(transport.SSE{Streams: transport.NewSSEStreams(), KeepAliveInterval: 10 * time.Millisecond})
+	addr := startTestServer(t, h.ServeGraphQL)
+	url := "http://" + addr + "/graphql"
+
+	resp := doSSERequest(t, "PUT", url, "", "")
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+	b, err := ioutil.ReadAll(resp.Body)
+	require.NoError(t, err)
+	token := string(b)
+	require.NotEmpty(t, token)
+
+	stream := doSSERequest(t, "GET", url, token, "")
+	defer stream.Body.Close()
+	require.Equal(t, http.StatusOK, stream.StatusCode)
+	events := bufio.NewReader(stream.Body)
+
+	resp = doSSERequest(t, "GET", url, token, "")
+	assert.Equal(t, http.StatusConflict, resp.StatusCode)
+
+	resp = doSSERequest(t, "POST", url, token, `{"query":"subscription { name }","extensions":{"operationId":"op1"}}`)
+	require.Equal(t, http.StatusAccepted, resp.StatusCode)
+
+	h.SendNextSubscriptionMessage()
+	event, data := readSSEEvent(t, events)
+	assert.Equal(t, "next", event)
+	assert.Equal(t, `{"id":"op1","payload":{"data":{"name":"test"}}}`, data)
+
+	resp = doSSERequest(t, "POST", url, token, `{"query":"{ name }"}`)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	resp = doSSERequest(t, "DELETE", url+"?operationId=op1", token, "")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	resp = doSSERequest(t, "POST", url, token, `{"query":"{ name }","extensions":{"operationId":"op2"}}`)
+	require.Equal(t, http.StatusAccepted, resp.StatusCode)
+
+	event, data = readSSEEvent(t, events)
+	assert.Equal(t, "next", event)
+	assert.Equal(t, `{"id":"op2","payload":{"data":{"name":"test"}}}`, data)
+	event, data = readSSEEvent(t, events)
+	assert.Equal(t, "complete", event)
+	assert.Equal(t, `{"id":"op2"}`, data)
+}
+
+func TestSSEReservations(t *testing.T) {
+	streams := transport.NewSSEStreams()
+	streams.ReservationTimeout = 50 * time.Millisecond
+	streams.MaxStreams = 1
+
+	h := testserver.New()
+	h.AddTransport(transport.SSE{Streams: streams})
+	addr := startTestServer(t, h.ServeGraphQL)
+	url := "http://" + addr + "/graphql"
+
+	resp := doSSERequest(t, "PUT", url, "", `{"query":"{ name }"}`)
+	assert.NotEqual(t, http.StatusCreated, resp.StatusCode, "only empty PUTs reserve a stream")
+
+	resp = doSSERequest(t, "PUT", url, "", "")
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+	b, err := ioutil.ReadAll(resp.Body)
+	require.NoError(t, err)
+	token := string(b)
+
+	resp = doSSERequest(t, "PUT", url, "", "")
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+
+	// the operation waits for the stream to be listened to, until the reservation expires
+	resp = doSSERequest(t, "POST", url, token, `{"query":"{ name }","extensions":{"operationId":"op1"}}`)
+	require.Equal(t, http.StatusAccepted, resp.StatusCode)
+
+	time.Sleep(100 * time.Millisecond)
+	resp = doSSERequest(t, "GET", url, token, "")
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	resp = doSSERequest(t, "POST", url, token, `{"query":"{ name }","extensions":{"operationId":"op2"}}`)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	resp = doSSERequest(t, "PUT", url, "", "")
+	assert.Equal(t, http.StatusCreated, resp.StatusCode, "the expired reservation is released")
+}
+
+func doSSERequest(t *testing.T, method, url, token, body string) *http.Response {
+	t.Helper()
+
+	req, err := http.NewRequest(method, url, strings.NewReader(body))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "text/event-stream")
+	if token != "" {
+		req.Header.Set("X-GraphQL-Event-Stream-Token", token)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	if method != "GET" || resp.StatusCode != http.StatusOK {
+		t.Cleanup(func() {
+			_ = resp.Body.Close()
+		})
+	}
+
+	return resp
+}
+
+func readSSEEvent(t *testing.T, r *bufio.Reader) (event, data string) {
+	t.Helper()
+
+	for {
+		line, err := r.ReadString('\n')
+		require.NoError(t, err)
+		line = strings.TrimSuffix(line, "\n")
+
+		switch {
+		case strings.HasPrefix(line, "event: "):
+			event = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			data = strings.TrimPrefix(line, "data: ")
+		case line == "" && event != "":
+			return event, data
+		}
+	}
+}
@@ -1,15 +1,81 @@
 package transport
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"time"
 
 	"github.com/99designs/gqlgen/graphql"
+	fibergqlgen "github.com/NickTaporuk/fiber-gqlgen"
 	"github.com/gofiber/fiber/v2"
+	"github.com/vektah/gqlparser/v2/ast"
 	"github.com/vektah/gqlparser/v2/gqlerror"
 )
 
+// writeJson encodes the response with the app's JSONEncoder, the Content-Type chosen by the transport is kept
 func writeJson(c *fiber.Ctx, response *graphql.Response) error {
-	return c.JSON(response)
+	b, err := c.App().Config().JSONEncoder(response)
+	if err != nil {
+		return err
+	}
+
+	applyResponse(c)
+	return c.Send(b)
+}
+
+// applyResponse writes the headers, cookies and status collected by resolvers and extensions, it
+// is called by every transport right before the body is written
+func applyResponse(c *fiber.Ctx) {
+	fibergqlgen.ResponseFromContext(c.UserContext()).Apply(c)
+}
+
+// rawParams decodes the request parameters along with the documentId of trusted documents, see
+// https://github.com/graphql/graphql-over-http/blob/main/rfcs/PersistedOperations.md
+type rawParams struct {
+	graphql.RawParams
+	DocumentID string `json:"documentId"`
+}
+
+// params returns the request parameters, the documentId is handed to extensions as the documentId
+// entry of the extensions
+func (p *rawParams) params() *graphql.RawParams {
+	setDocumentID(&p.RawParams, p.DocumentID)
+	return &p.RawParams
+}
+
+func setDocumentID(params *graphql.RawParams, id string) {
+	if id == "" {
+		return
+	}
+	if params.Extensions == nil {
+		params.Extensions = map[string]interface{}{}
+	}
+	params.Extensions["documentId"] = id
+}
+
+// detachedContext keeps the values of a request context without its cancellation, for work that
+// outlives the request it was started by
+type detachedContext struct {
+	parent context.Context
+}
+
+func (detachedContext) Deadline() (time.Time, bool) {
+	return time.Time{}, false
+}
+
+func (detachedContext) Done() <-chan struct{} {
+	return nil
+}
+
+func (detachedContext) Err() error {
+	return nil
+}
+
+func (d detachedContext) Value(key interface{}) interface{} {
+	return d.parent.Value(key)
 }
 
 func writeJsonError(c *fiber.Ctx, msg string) error {
@@ -23,3 +89,87 @@ func writeJsonErrorf(c *fiber.Ctx, format string, args ...interface{}) error {
 func writeJsonGraphqlError(c *fiber.Ctx, err ...*gqlerror.Error) error {
 	return writeJson(c, &graphql.Response{Errors: err})
 }
+
+func jsonDecode(r io.Reader, val interface{}) error {
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+	return dec.Decode(val)
+}
+
+// responseStream holds the responses of an operation streamed to the client
+type responseStream struct {
+	ctx       context.Context
+	rc        *graphql.OperationContext
+	responses graphql.ResponseHandler
+	// first is the response read by startStream, when readAhead is set
+	first     *graphql.Response
+	readAhead bool
+	// err is a panic recovered by startStream, it is sent in place of the responses
+	err *gqlerror.Error
+}
+
+// startStream runs the operation up to its first response before anything is written, so the headers,
+// cookies and status set by resolvers and extensions meanwhile can be applied to the stream. The first
+// event of a subscription may take a long time, only what is set while subscribing is applied to it.
+func startStream(ctx context.Context, exec graphql.GraphExecutor, rc *graphql.OperationContext) (s *responseStream) {
+	s = &responseStream{ctx: ctx, rc: rc}
+	defer func() {
+		if r := recover(); r != nil {
+			s.err = s.recovered(r)
+		}
+	}()
+
+	s.responses, s.ctx = exec.DispatchOperation(ctx, rc)
+	if rc.Operation == nil || rc.Operation.Operation != ast.Subscription {
+		s.first, s.readAhead = s.responses(s.ctx), true
+	}
+	return s
+}
+
+// stream hands every response to send, encoded by encode. It stops as soon as send reports the stream
+// is gone.
+func (s *responseStream) stream(send func([]byte) bool, encode func(*graphql.Response) []byte) {
+	defer func() {
+		if r := recover(); r != nil {
+			send(encode(&graphql.Response{Errors: gqlerror.List{s.recovered(r)}}))
+		}
+	}()
+
+	if s.err != nil {
+		send(encode(&graphql.Response{Errors: gqlerror.List{s.err}}))
+		return
+	}
+
+	response := s.first
+	if !s.readAhead {
+		response = s.responses(s.ctx)
+	}
+	for response != nil {
+		if !send(encode(response)) {
+			return
+		}
+		response = s.responses(s.ctx)
+	}
+}
+
+func (s *responseStream) recovered(r interface{}) *gqlerror.Error {
+	err := s.rc.Recover(s.ctx, r)
+	var gqlerr *gqlerror.Error
+	if !errors.As(err, &gqlerr) {
+		gqlerr = &gqlerror.Error{}
+		if err != nil {
+			gqlerr.Message = err.Error()
+		}
+	}
+	return gqlerr
+}
+
+func contains(list []string, elem string) bool {
+	for _, e := range list {
+		if e == elem {
+			return true
+		}
+	}
+
+	return false
+}
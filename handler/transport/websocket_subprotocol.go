@@ -0,0 +1,103 @@
+package transport
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/fasthttp/websocket"
+)
+
+const (
+	initMessageType messageType = iota
+	connectionAckMessageType
+	keepAliveMessageType
+	connectionErrorMessageType
+	connectionCloseMessageType
+	startMessageType
+	stopMessageType
+	dataMessageType
+	completeMessageType
+	errorMessageType
+	pingMessageType
+	pongMessageType
+)
+
+var (
+	supportedSubprotocols = []string{
+		graphqlwsSubprotocol,
+		graphqltransportwsSubprotocol,
+	}
+
+	errWsConnClosed = errors.New("websocket connection closed")
+	errInvalidMsg   = errors.New("invalid message received")
+)
+
+type (
+	messageType int
+	message     struct {
+		payload json.RawMessage
+		id      string
+		t       messageType
+	}
+	messageExchanger interface {
+		NextMessage() (message, error)
+		Send(m *message) error
+	}
+)
+
+func (t messageType) String() string {
+	var text string
+	switch t {
+	default:
+		text = "unknown"
+	case initMessageType:
+		text = "init"
+	case connectionAckMessageType:
+		text = "connection ack"
+	case keepAliveMessageType:
+		text = "keep alive"
+	case connectionErrorMessageType:
+		text = "connection error"
+	case connectionCloseMessageType:
+		text = "connection close"
+	case startMessageType:
+		text = "start"
+	case stopMessageType:
+		text = "stop subscription"
+	case dataMessageType:
+		text = "data"
+	case completeMessageType:
+		text = "complete"
+	case errorMessageType:
+		text = "error"
+	case pingMessageType:
+		text = "ping"
+	case pongMessageType:
+		text = "pong"
+	}
+	return text
+}
+
+// upgrader returns a copy of the Upgrader with the graphql specific subprotocols added. The list of
+// subprotocols is specified by the consumer of the Websocket struct, in order to preserve backward
+// compatibility the graphql ones are injected at runtime, without touching the slice of the consumer.
+func (t Websocket) upgrader() websocket.FastHTTPUpgrader {
+	upgrader := t.Upgrader
+	upgrader.Subprotocols = append([]string(nil), t.Upgrader.Subprotocols...)
+	for _, subprotocol := range supportedSubprotocols {
+		if !contains(upgrader.Subprotocols, subprotocol) {
+			upgrader.Subprotocols = append(upgrader.Subprotocols, subprotocol)
+		}
+	}
+	return upgrader
+}
+
+func handleNextReaderError(err error) error {
+	// TODO: should we consider all closure scenarios here for the ws connection?
+	// for now we only list the error codes from the previous implementation
+	if websocket.IsCloseError(err, websocket.CloseNormalClosure, websocket.CloseNoStatusReceived) {
+		return errWsConnClosed
+	}
+
+	return err
+}
@@ -0,0 +1,184 @@
+package transport_test
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/NickTaporuk/fiber-gqlgen/handler/testserver"
+	"github.com/NickTaporuk/fiber-gqlgen/handler/transport"
+	"github.com/fasthttp/websocket"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/require"
+)
+
+type wsMessage struct {
+	Type    string          `json:"type"`
+	ID      string          `json:"id,omitempty"`
+	Payload json.RawMessage `json:"payload,omitempty"`
+}
+
+func TestWebsocket(t *testing.T) {
+	h := testserver.New()
+	h.AddTransport(transport.Websocket{})
+	addr := startTestServer(t, h.ServeGraphQL)
+
+	t.Run("graphql-ws", func(t *testing.T) {
+		c := dialWebsocket(t, addr, "graphql-ws")
+		defer c.Close()
+
+		require.NoError(t, c.WriteJSON(&wsMessage{Type: "connection_init"}))
+		require.Equal(t, "connection_ack", readWsMsg(t, c).Type)
+		require.Equal(t, "ka", readWsMsg(t, c).Type)
+
+		require.NoError(t, c.WriteJSON(&wsMessage{
+			Type:    "start",
+			ID:      "test_1",
+			Payload: json.RawMessage(`{"query": "subscription { name }"}`),
+		}))
+
+		h.SendNextSubscriptionMessage()
+		msg := readWsMsg(t, c)
+		require.Equal(t, "data", msg.Type)
+		require.Equal(t, "test_1", msg.ID)
+		require.Equal(t, `{"data":{"name":"test"}}`, string(msg.Payload))
+
+		require.NoError(t, c.WriteJSON(&wsMessage{Type: "stop", ID: "test_1"}))
+		msg = readWsMsg(t, c)
+		require.Equal(t, "complete", msg.Type)
+		require.Equal(t, "test_1", msg.ID)
+	})
+
+	t.Run("graphql-transport-ws", func(t *testing.T) {
+		c := dialWebsocket(t, addr, "graphql-transport-ws")
+		defer c.Close()
+
+		require.NoError(t, c.WriteJSON(&wsMessage{Type: "connection_init"}))
+		require.Equal(t, "connection_ack", readWsMsg(t, c).Type)
+
+		require.NoError(t, c.WriteJSON(&wsMessage{Type: "ping"}))
+		require.Equal(t, "pong", readWsMsg(t, c).Type)
+
+		require.NoError(t, c.WriteJSON(&wsMessage{
+			Type:    "subscribe",
+			ID:      "test_1",
+			Payload: json.RawMessage(`{"query": "subscription { name }"}`),
+		}))
+
+		h.SendNextSubscriptionMessage()
+		msg := readWsMsg(t, c)
+		require.Equal(t, "next", msg.Type)
+		require.Equal(t, "test_1", msg.ID)
+		require.Equal(t, `{"data":{"name":"test"}}`, string(msg.Payload))
+
+		require.NoError(t, c.WriteJSON(&wsMessage{Type: "complete", ID: "test_1"}))
+		msg = readWsMsg(t, c)
+		require.Equal(t, "complete", msg.Type)
+		require.Equal(t, "test_1", msg.ID)
+	})
+
+	t.Run("parse errors are sent on the operation", func(t *testing.T) {
+		c := dialWebsocket(t, addr, "graphql-transport-ws")
+		defer c.Close()
+
+		require.NoError(t, c.WriteJSON(&wsMessage{Type: "connection_init"}))
+		require.Equal(t, "connection_ack", readWsMsg(t, c).Type)
+
+		require.NoError(t, c.WriteJSON(&wsMessage{
+			Type:    "subscribe",
+			ID:      "test_1",
+			Payload: json.RawMessage(`{"query": "!"}`),
+		}))
+
+		msg := readWsMsg(t, c)
+		require.Equal(t, "error", msg.Type)
+		require.Equal(t, `[{"message":"Unexpected !","locations":[{"line":1,"column":1}],"extensions":{"code":"GRAPHQL_PARSE_FAILED"}}]`, string(msg.Payload))
+		require.Equal(t, "complete", readWsMsg(t, c).Type)
+	})
+}
+
+func TestWebsocketInitTimeout(t *testing.T) {
+	h := testserver.New()
+	h.AddTransport(transport.Websocket{InitTimeout: 50 * time.Millisecond})
+	addr := startTestServer(t, h.ServeGraphQL)
+
+	c := dialWebsocket(t, addr, "graphql-transport-ws")
+	defer c.Close()
+
+	_, _, err := c.ReadMessage()
+	require.True(t, websocket.IsCloseError(err, websocket.CloseProtocolError), err)
+}
+
+func TestWebsocketCloseFunc(t *testing.T) {
+	closed := make(chan int, 4)
+	subprotocols := make([]string, 1, 4)
+	subprotocols[0] = "custom"
+
+	h := testserver.New()
+	h.AddTransport(transport.Websocket{
+		Upgrader: websocket.FastHTTPUpgrader{Subprotocols: subprotocols},
+		CloseFunc: func(ctx context.Context, closeCode int) {
+			closed <- closeCode
+		},
+	})
+	addr := startTestServer(t, h.ServeGraphQL)
+
+	c := dialWebsocket(t, addr, "graphql-ws")
+	defer c.Close()
+
+	require.NoError(t, c.WriteJSON(&wsMessage{Type: "connection_init"}))
+	require.Equal(t, "connection_ack", readWsMsg(t, c).Type)
+	require.NoError(t, c.WriteJSON(&wsMessage{Type: "connection_terminate"}))
+
+	select {
+	case code := <-closed:
+		require.Equal(t, websocket.CloseNormalClosure, code)
+	case <-time.After(time.Second):
+		t.Fatal("CloseFunc was not called")
+	}
+	time.Sleep(50 * time.Millisecond)
+	require.Len(t, closed, 0, "CloseFunc is called once")
+	require.Equal(t, []string{"custom", ""}, subprotocols[:2], "the subprotocols of the Upgrader are left untouched")
+}
+
+func startTestServer(t *testing.T, handler fiber.Handler) string {
+	t.Helper()
+
+	app := fiber.New(fiber.Config{DisableStartupMessage: true})
+	app.All("/graphql", handler)
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go func() {
+		_ = app.Listener(ln)
+	}()
+	t.Cleanup(func() {
+		_ = app.Shutdown()
+	})
+
+	return ln.Addr().String()
+}
+
+func dialWebsocket(t *testing.T, addr string, subprotocol string) *websocket.Conn {
+	t.Helper()
+
+	dialer := websocket.Dialer{Subprotocols: []string{subprotocol}}
+	c, resp, err := dialer.Dial("ws://"+addr+"/graphql", nil)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	require.Equal(t, subprotocol, c.Subprotocol())
+
+	return c
+}
+
+func readWsMsg(t *testing.T, c *websocket.Conn) wsMessage {
+	t.Helper()
+
+	var m wsMessage
+	require.NoError(t, c.SetReadDeadline(time.Now().Add(time.Second)))
+	require.NoError(t, c.ReadJSON(&m))
+
+	return m
+}
@@ -0,0 +1,121 @@
+package playground
+
+import (
+	"html/template"
+
+	"github.com/NickTaporuk/fiber-gqlgen/playground/internal/assets"
+	"github.com/gofiber/fiber/v2"
+)
+
+var altairPage = template.Must(template.New("altair").Parse(`<!DOCTYPE html>
+<html>
+  <head>
+    <meta charset="utf-8">
+    <title>{{.title}}</title>
+    <base href="{{.base}}">
+    <meta name="viewport" content="width=device-width,initial-scale=1">
+    <link rel="icon" type="image/x-icon" href="favicon.ico">
+{{- range .styles}}
+    <link
+		rel="stylesheet"
+		href="{{.URL}}"
+		{{- with .Integrity}}
+		integrity="{{.}}"
+		{{- end}}
+		{{- with $.nonce}}
+		nonce="{{.}}"
+		{{- end}}
+		crossorigin="anonymous"
+	/>
+{{- end}}
+  </head>
+  <body>
+    <app-root>
+      <style{{with .nonce}} nonce="{{.}}"{{end}}>
+        .loading-screen {
+          display: none;
+        }
+      </style>
+      <div class="loading-screen styled">
+        <div class="loading-screen-inner">
+          <div class="loading-screen-logo-container">
+            <img src="assets/img/logo_350.svg" alt="Altair">
+          </div>
+          <div class="loading-screen-loading-indicator">
+            <span class="loading-indicator-dot"></span>
+            <span class="loading-indicator-dot"></span>
+            <span class="loading-indicator-dot"></span>
+          </div>
+        </div>
+      </div>
+    </app-root>
+{{- range .scripts}}
+	<script
+		src="{{.URL}}"
+		{{- with .Integrity}}
+		integrity="{{.}}"
+		{{- end}}
+		{{- with $.nonce}}
+		nonce="{{.}}"
+		{{- end}}
+		crossorigin="anonymous"
+	></script>
+{{- end}}
+    <script{{with .nonce}} nonce="{{.}}"{{end}}>
+      const url = location.protocol + '//' + location.host + {{.endpoint}};
+      const wsProto = location.protocol == 'https:' ? 'wss:' : 'ws:';
+      const subscriptionUrl = {{if .subscriptionAbsolute}}{{.subscriptionEndpoint}}{{else}}wsProto + '//' + location.host + {{.subscriptionEndpoint}}{{end}};
+      const options = {{.options}};
+      window.addEventListener('load', function () {
+        AltairGraphQL.init(Object.assign({ endpointURL: url, subscriptionsEndpoint: subscriptionUrl }, options));
+      });
+    </script>
+  </body>
+</html>
+`))
+
+// Altair creates a handler serving the Altair GraphQL client, pinned to altair-static 5.0.5 loaded from
+// the CDN. It uses Title, Endpoint, SubscriptionEndpoint, DefaultHeaders, InitialQuery, InitialVariables
+// and Next of the config, and panics when the config embeds the assets.
+func Altair(config ...Config) fiber.Handler {
+	// Set default config
+	cfg := configDefault(config...)
+	cdnOnly(cfg, "Altair")
+
+	options := map[string]interface{}{}
+	if cfg.InitialQuery != "" {
+		options["initialQuery"] = cfg.InitialQuery
+	}
+	if cfg.InitialVariables != "" {
+		options["initialVariables"] = cfg.InitialVariables
+	}
+	if len(cfg.DefaultHeaders) > 0 {
+		options["initialHeaders"] = cfg.DefaultHeaders
+	}
+
+	styles := []pageAsset{cdnAsset(assets.AltairCSS)}
+	scripts := []pageAsset{cdnAsset(assets.AltairMain), cdnAsset(assets.AltairPolyfills), cdnAsset(assets.AltairRuntime)}
+
+	return func(c *fiber.Ctx) error {
+		// Don't execute middleware if Next returns true
+		if cfg.Next != nil && cfg.Next(c) {
+			return c.Next()
+		}
+
+		return execute(c, cfg, altairPage, map[string]interface{}{
+			"title":                cfg.Title,
+			"base":                 assets.CDN + assets.AltairBase,
+			"endpoint":             cfg.Endpoint,
+			"subscriptionEndpoint": cfg.SubscriptionEndpoint,
+			"subscriptionAbsolute": isWebSocketURL(cfg.SubscriptionEndpoint),
+			"styles":               styles,
+			"scripts":              scripts,
+			"options":              options,
+		})
+	}
+}
+
+// cdnAsset returns the page asset loading asset from the CDN
+func cdnAsset(asset assets.Asset) pageAsset {
+	return pageAsset{URL: assets.CDN + asset.Path, Integrity: asset.Integrity}
+}
@@ -0,0 +1,99 @@
+package playground
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+
+	"github.com/NickTaporuk/fiber-gqlgen/playground/internal/assets"
+	"github.com/gofiber/fiber/v2"
+)
+
+var apolloSandboxPage = template.Must(template.New("apollo-sandbox").Parse(`<!DOCTYPE html>
+<html>
+  <head>
+    <meta charset="utf-8">
+    <title>{{.title}}</title>
+    <meta name="viewport" content="width=device-width,initial-scale=1">
+    <link rel="icon" href="https://embeddable-sandbox.cdn.apollographql.com/_latest/public/assets/favicon-dark.png">
+    <style{{with .nonce}} nonce="{{.}}"{{end}}>
+      body {
+        margin: 0;
+        overflow: hidden;
+      }
+      #embedded-sandbox {
+        width: 100vw;
+        height: 100vh;
+      }
+    </style>
+  </head>
+  <body>
+    <div id="embedded-sandbox"></div>
+{{- range .scripts}}
+	<script
+		src="{{.URL}}"
+		{{- with .Integrity}}
+		integrity="{{.}}"
+		{{- end}}
+		{{- with $.nonce}}
+		nonce="{{.}}"
+		{{- end}}
+		crossorigin="anonymous"
+	></script>
+{{- end}}
+    <script{{with .nonce}} nonce="{{.}}"{{end}}>
+      const url = location.protocol + '//' + location.host + {{.endpoint}};
+      const initialState = {{.initialState}};
+      new window.EmbeddedSandbox({
+        target: '#embedded-sandbox',
+        initialEndpoint: url,
+        persistExplorerState: true,
+        initialState: initialState,
+      });
+    </script>
+  </body>
+</html>
+`))
+
+// ApolloSandbox creates a handler serving the embeddable Apollo Sandbox, pinned to the build of the
+// sandbox loaded from Apollo's CDN. It uses Title, Endpoint, DefaultHeaders, InitialQuery, InitialVariables
+// and Next of the config, and panics when the config embeds the assets or InitialVariables is not a JSON object.
+func ApolloSandbox(config ...Config) fiber.Handler {
+	// Set default config
+	cfg := configDefault(config...)
+	cdnOnly(cfg, "Apollo Sandbox")
+
+	initialState := map[string]interface{}{
+		"includeCookies":       true,
+		"pollForSchemaUpdates": false,
+	}
+	if cfg.InitialQuery != "" {
+		initialState["document"] = cfg.InitialQuery
+	}
+	if cfg.InitialVariables != "" {
+		var variables map[string]interface{}
+		if err := json.Unmarshal([]byte(cfg.InitialVariables), &variables); err != nil {
+			panic(fmt.Sprintf("playground: the initial variables of Apollo Sandbox are not a JSON object: %s", err))
+		}
+		initialState["variables"] = variables
+	}
+	if len(cfg.DefaultHeaders) > 0 {
+		initialState["headers"] = cfg.DefaultHeaders
+	}
+
+	scripts := []pageAsset{{URL: assets.ApolloSandboxCDN + assets.ApolloSandbox.Path, Integrity: assets.ApolloSandbox.Integrity}}
+
+	return func(c *fiber.Ctx) error {
+		// Don't execute middleware if Next returns true
+		if cfg.Next != nil && cfg.Next(c) {
+			return c.Next()
+		}
+
+		return execute(c, cfg, apolloSandboxPage, map[string]interface{}{
+			"title":        cfg.Title,
+			"endpoint":     cfg.Endpoint,
+			"scripts":      scripts,
+			"initialState": initialState,
+		})
+	}
+}
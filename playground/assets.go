@@ -0,0 +1,98 @@
+package playground
+
+import (
+	"crypto/sha256"
+	"embed"
+	"encoding/base64"
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+
+	"github.com/NickTaporuk/fiber-gqlgen/playground/internal/assets"
+	"github.com/gofiber/fiber/v2"
+)
+
+//go:generate go run ./internal/fetchassets -dir assets
+
+// The modes of loading the GraphiQL, React and ReactDOM assets of the playground page
+const (
+	// AssetsCDN loads the assets from cdn.jsdelivr.net
+	AssetsCDN = "cdn"
+
+	// AssetsEmbedded serves the assets embedded in the binary from the assets sub-route of the page,
+	// the browser does not need any network access besides the server
+	AssetsEmbedded = "embedded"
+)
+
+// assetsRoute is the sub-route of the page the embedded assets are served from
+const assetsRoute = "/assets/"
+
+//go:embed assets
+var embedded embed.FS
+
+// assetsFS holds the embedded assets, it is replaced in tests
+var assetsFS fs.FS = mustSub(embedded, "assets")
+
+// assetURL returns the url of the asset at path for a page served at base
+func assetURL(mode, base, path string) string {
+	if mode == AssetsEmbedded {
+		return strings.TrimSuffix(base, "/") + assetsRoute + path
+	}
+	return assets.CDN + path
+}
+
+// verifyAssets checks that every asset of list is in fsys and matches its integrity
+func verifyAssets(fsys fs.FS, list []assets.Asset) error {
+	for _, asset := range list {
+		b, err := fs.ReadFile(fsys, asset.Path)
+		if err != nil {
+			return fmt.Errorf("playground: asset %s is not embedded, run go generate in the playground package", asset.Path)
+		}
+		sum := sha256.Sum256(b)
+		if "sha256-"+base64.StdEncoding.EncodeToString(sum[:]) != asset.Integrity {
+			return fmt.Errorf("playground: embedded asset %s does not match its integrity %s", asset.Path, asset.Integrity)
+		}
+	}
+	return nil
+}
+
+// serveAsset serves the embedded asset requested below the assets sub-route, ok is false when the
+// request is not for one of the assets
+func serveAsset(c *fiber.Ctx) (ok bool, err error) {
+	i := strings.LastIndex(c.Path(), assetsRoute)
+	if i < 0 {
+		return false, nil
+	}
+	name := c.Path()[i+len(assetsRoute):]
+
+	for _, asset := range assets.All {
+		if asset.Path != name {
+			continue
+		}
+		b, err := fs.ReadFile(assetsFS, asset.Path)
+		if err != nil {
+			return true, err
+		}
+
+		switch path.Ext(name) {
+		case ".css":
+			c.Set(fiber.HeaderContentType, "text/css; charset=utf-8")
+		case ".js":
+			c.Set(fiber.HeaderContentType, "application/javascript; charset=utf-8")
+		}
+		// the paths carry the version of the asset
+		c.Set(fiber.HeaderCacheControl, "public, max-age=31536000, immutable")
+		return true, c.Send(b)
+	}
+
+	return false, nil
+}
+
+func mustSub(fsys fs.FS, dir string) fs.FS {
+	sub, err := fs.Sub(fsys, dir)
+	if err != nil {
+		panic(err)
+	}
+	return sub
+}
@@ -0,0 +1,124 @@
+package playground
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"html"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+
+	"github.com/NickTaporuk/fiber-gqlgen/playground/internal/assets"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func integrity(b []byte) string {
+	sum := sha256.Sum256(b)
+	return "sha256-" + base64.StdEncoding.EncodeToString(sum[:])
+}
+
+func TestAssetURL(t *testing.T) {
+	assert.Equal(t, "https://cdn.jsdelivr.net/npm/graphiql@1.5.16/graphiql.min.js", assetURL(AssetsCDN, "/playground", assets.GraphiQLJS.Path))
+	assert.Equal(t, "/playground/assets/graphiql@1.5.16/graphiql.min.js", assetURL(AssetsEmbedded, "/playground/", assets.GraphiQLJS.Path))
+}
+
+func TestVerifyAssets(t *testing.T) {
+	fsys := fstest.MapFS{"lib@1.0.0/lib.js": {Data: []byte("lib")}}
+
+	assert.NoError(t, verifyAssets(fsys, []assets.Asset{{Path: "lib@1.0.0/lib.js", Integrity: integrity([]byte("lib"))}}))
+	assert.EqualError(t, verifyAssets(fsys, []assets.Asset{{Path: "lib@1.0.0/lib.js", Integrity: integrity([]byte("other"))}}),
+		"playground: embedded asset lib@1.0.0/lib.js does not match its integrity "+integrity([]byte("other")))
+	assert.EqualError(t, verifyAssets(fsys, []assets.Asset{{Path: "missing.js"}}),
+		"playground: asset missing.js is not embedded, run go generate in the playground package")
+}
+
+func TestServeAsset(t *testing.T) {
+	embeddedFS := assetsFS
+	defer func() { assetsFS = embeddedFS }()
+	assetsFS = fstest.MapFS{
+		assets.GraphiQLCSS.Path: {Data: []byte("css")},
+		assets.GraphiQLJS.Path:  {Data: []byte("js")},
+	}
+
+	app := fiber.New()
+	app.Get("/playground/*", func(c *fiber.Ctx) error {
+		if ok, err := serveAsset(c); ok {
+			return err
+		}
+		return c.SendString("page")
+	})
+
+	get := func(t *testing.T, target string) (*http.Response, string) {
+		resp, err := app.Test(httptest.NewRequest("GET", target, nil))
+		require.NoError(t, err)
+		b, err := ioutil.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return resp, string(b)
+	}
+
+	resp, body := get(t, "/playground/assets/"+assets.GraphiQLJS.Path)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "application/javascript; charset=utf-8", resp.Header.Get("Content-Type"))
+	assert.Equal(t, "public, max-age=31536000, immutable", resp.Header.Get("Cache-Control"))
+	assert.Equal(t, "js", body)
+
+	resp, body = get(t, "/playground/assets/"+assets.GraphiQLCSS.Path)
+	assert.Equal(t, "text/css; charset=utf-8", resp.Header.Get("Content-Type"))
+	assert.Equal(t, "css", body)
+
+	_, body = get(t, "/playground/assets/other.js")
+	assert.Equal(t, "page", body, "only the assets of the page are served")
+}
+
+func TestNewAssets(t *testing.T) {
+	app := fiber.New()
+	app.Get("/playground", New(Config{Endpoint: "/graphql"}))
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/playground", nil))
+	require.NoError(t, err)
+	b, err := ioutil.ReadAll(resp.Body)
+	require.NoError(t, err)
+	page := html.UnescapeString(string(b))
+	for _, asset := range assets.All {
+		assert.Contains(t, page, `"https://cdn.jsdelivr.net/npm/`+asset.Path+`"`)
+		assert.Contains(t, page, `integrity="`+asset.Integrity+`"`)
+	}
+
+	if verifyAssets(assetsFS, assets.All) != nil {
+		assert.Panics(t, func() { New(Config{Assets: AssetsEmbedded}) }, "the embedded mode requires the vendored assets")
+	}
+}
+
+func TestNewEmbeddedAssets(t *testing.T) {
+	if err := verifyAssets(assetsFS, assets.All); err != nil {
+		t.Skipf("the assets are not vendored, run go generate ./playground: %s", err)
+	}
+
+	h := New(Config{Endpoint: "/graphql", Assets: AssetsEmbedded})
+	app := fiber.New()
+	app.Get("/playground", h)
+	app.Get("/playground/assets/*", h)
+
+	get := func(t *testing.T, target string) (*http.Response, []byte) {
+		resp, err := app.Test(httptest.NewRequest("GET", target, nil))
+		require.NoError(t, err)
+		b, err := ioutil.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return resp, b
+	}
+
+	_, b := get(t, "/playground")
+	page := html.UnescapeString(string(b))
+	for _, asset := range assets.All {
+		assert.Contains(t, page, `"/playground/assets/`+asset.Path+`"`)
+		assert.NotContains(t, page, assets.CDN+asset.Path)
+
+		resp, b := get(t, "/playground/assets/"+asset.Path)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, asset.Integrity, integrity(b), "the embedded file of %s is served", asset.Path)
+	}
+}
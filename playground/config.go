@@ -1,6 +1,11 @@
 package playground
 
-import "github.com/gofiber/fiber/v2"
+import (
+	"strings"
+
+	"github.com/NickTaporuk/fiber-gqlgen/playground/internal/assets"
+	"github.com/gofiber/fiber/v2"
+)
 
 // Config defines the config for middleware.
 type Config struct {
@@ -14,16 +19,118 @@ type Config struct {
 	// Optional. Default: /query
 	Endpoint string
 
+	// SubscriptionEndpoint defines the endpoint of subscriptions, either a path on the host of the page or
+	// an absolute ws:// or wss:// url.
+	//
+	// Optional. Default: Endpoint
+	SubscriptionEndpoint string
+
+	// GraphiQLVersion defines the version of GraphiQL loaded from the CDN. Set the integrity of its files
+	// along with it, they are loaded without subresource integrity otherwise.
+	//
+	// Optional. Default: 1.5.16
+	GraphiQLVersion string
+
+	// GraphiQLCSSIntegrity defines the subresource integrity of graphiql.min.css.
+	//
+	// Optional. Default: the integrity of the default GraphiQLVersion
+	GraphiQLCSSIntegrity string
+
+	// GraphiQLJSIntegrity defines the subresource integrity of graphiql.min.js.
+	//
+	// Optional. Default: the integrity of the default GraphiQLVersion
+	GraphiQLJSIntegrity string
+
+	// DefaultHeaders defines the headers the header editor starts with.
+	//
+	// Optional. Default: nil
+	DefaultHeaders map[string]string
+
+	// InitialQuery defines the query the editor starts with when the browser has no stored query.
+	//
+	// Optional. Default: "" (the GraphiQL welcome comment)
+	InitialQuery string
+
+	// InitialVariables defines the variables, as JSON, the variables editor starts with.
+	//
+	// Optional. Default: ""
+	InitialVariables string
+
+	// Tabs defines the tabs opened when the browser has no stored tabs, they replace InitialQuery and
+	// InitialVariables. Requires GraphiQL 2 or later.
+	//
+	// Optional. Default: nil
+	Tabs []Tab
+
+	// ExplorerPluginVersion defines the version of @graphiql/plugin-explorer loaded from the CDN, which
+	// enables the explorer plugin. Requires GraphiQL 2 or later and the 0.1 plugin API.
+	//
+	// Optional. Default: "" (no explorer)
+	ExplorerPluginVersion string
+
+	// ExplorerPluginCSSIntegrity defines the subresource integrity of the stylesheet of the explorer plugin.
+	//
+	// Optional. Default: ""
+	ExplorerPluginCSSIntegrity string
+
+	// ExplorerPluginJSIntegrity defines the subresource integrity of the script of the explorer plugin.
+	//
+	// Optional. Default: ""
+	ExplorerPluginJSIntegrity string
+
+	// VoyagerCSSIntegrity defines the subresource integrity of voyager.css of graphql-voyager 2.0.0.
+	//
+	// Required by Voyager, which panics without it until the default is pinned. Default: ""
+	VoyagerCSSIntegrity string
+
+	// VoyagerJSIntegrity defines the subresource integrity of voyager.standalone.js of graphql-voyager 2.0.0.
+	//
+	// Required by Voyager, which panics without it until the default is pinned. Default: ""
+	VoyagerJSIntegrity string
+
+	// Assets defines where the browser loads GraphiQL, React and ReactDOM from, AssetsCDN or AssetsEmbedded.
+	// Embedded assets are served from the assets sub-route of the page, so register the handler for it as
+	// well, eg app.Get("/playground/*", playground.New(cfg)). The other pages are only loaded from the CDN.
+	//
+	// Optional. Default: AssetsCDN
+	Assets string
+
+	// Nonce defines a function returning the nonce of the request, eg the one a CSP middleware stored in
+	// the locals, which is set on every script and style tag of the page.
+	//
+	// Optional. Default: nil (no nonce), GenerateNonce when ContentSecurityPolicy contains {nonce}
+	Nonce func(c *fiber.Ctx) string
+
+	// ContentSecurityPolicy defines the Content-Security-Policy header of the page, every {nonce} in it is
+	// replaced with the nonce of the request, eg "script-src 'nonce-{nonce}' https://cdn.jsdelivr.net".
+	//
+	// Optional. Default: "" (no header)
+	ContentSecurityPolicy string
+
 	// Next defines a function to skip this middleware when returned true.
 	//
 	// Optional. Default: nil
 	Next func(c *fiber.Ctx) bool
 }
 
+// Tab is a tab of the GraphiQL editor
+type Tab struct {
+	Query     string `json:"query"`
+	Variables string `json:"variables,omitempty"`
+	Headers   string `json:"headers,omitempty"`
+}
+
 var ConfigDefault = Config{
-	Title:    "Fiber GraphQL",
-	Endpoint: "/query",
-	Next:     nil,
+	Title:                "Fiber GraphQL",
+	Endpoint:             "/query",
+	SubscriptionEndpoint: "/query",
+	GraphiQLVersion:      assets.GraphiQLVersion,
+	GraphiQLCSSIntegrity: assets.GraphiQLCSS.Integrity,
+	GraphiQLJSIntegrity:  assets.GraphiQLJS.Integrity,
+	VoyagerCSSIntegrity:  assets.VoyagerCSS.Integrity,
+	VoyagerJSIntegrity:   assets.VoyagerJS.Integrity,
+	Assets:               AssetsCDN,
+	Next:                 nil,
 }
 
 func configDefault(config ...Config) Config {
@@ -44,6 +151,39 @@ func configDefault(config ...Config) Config {
 		cfg.Endpoint = ConfigDefault.Endpoint
 	}
 
+	if cfg.SubscriptionEndpoint == "" {
+		cfg.SubscriptionEndpoint = cfg.Endpoint
+	}
+
+	// the default integrity only matches the default version
+	if cfg.GraphiQLVersion == "" {
+		cfg.GraphiQLVersion = ConfigDefault.GraphiQLVersion
+	}
+
+	if cfg.GraphiQLVersion == ConfigDefault.GraphiQLVersion && cfg.GraphiQLCSSIntegrity == "" {
+		cfg.GraphiQLCSSIntegrity = ConfigDefault.GraphiQLCSSIntegrity
+	}
+
+	if cfg.GraphiQLVersion == ConfigDefault.GraphiQLVersion && cfg.GraphiQLJSIntegrity == "" {
+		cfg.GraphiQLJSIntegrity = ConfigDefault.GraphiQLJSIntegrity
+	}
+
+	if cfg.VoyagerCSSIntegrity == "" {
+		cfg.VoyagerCSSIntegrity = ConfigDefault.VoyagerCSSIntegrity
+	}
+
+	if cfg.VoyagerJSIntegrity == "" {
+		cfg.VoyagerJSIntegrity = ConfigDefault.VoyagerJSIntegrity
+	}
+
+	if cfg.Assets == "" {
+		cfg.Assets = ConfigDefault.Assets
+	}
+
+	if cfg.Nonce == nil && strings.Contains(cfg.ContentSecurityPolicy, "{nonce}") {
+		cfg.Nonce = GenerateNonce
+	}
+
 	if cfg.Next == nil {
 		cfg.Next = ConfigDefault.Next
 	}
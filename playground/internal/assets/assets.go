@@ -0,0 +1,89 @@
+// Package assets lists the files the playground pages load, the GraphiQL ones are shared by the
+// playground and the fetchassets generator that vendors them for the embedded mode.
+package assets
+
+// CDN is the root the assets are loaded from in the CDN mode
+const CDN = "https://cdn.jsdelivr.net/npm/"
+
+// GraphiQLVersion is the version of GraphiQL the assets are pinned to
+const GraphiQLVersion = "1.5.16"
+
+// Asset is a file of the playground page
+type Asset struct {
+	// Path is the path of the asset below CDN, and below the assets directory when embedded
+	Path string
+
+	// Integrity is the subresource integrity of the asset
+	Integrity string
+}
+
+var (
+	GraphiQLCSS = Asset{
+		Path:      "graphiql@" + GraphiQLVersion + "/graphiql.min.css",
+		Integrity: "sha256-HADQowUuFum02+Ckkv5Yu5ygRoLllHZqg0TFZXY7NHI=",
+	}
+	GraphiQLJS = Asset{
+		Path:      "graphiql@" + GraphiQLVersion + "/graphiql.min.js",
+		Integrity: "sha256-uHp12yvpXC4PC9+6JmITxKuLYwjlW9crq9ywPE5Rxco=",
+	}
+	React = Asset{
+		Path:      "react@17.0.2/umd/react.production.min.js",
+		Integrity: "sha256-Ipu/TQ50iCCVZBUsZyNJfxrDk0E2yhaEIz0vqI+kFG8=",
+	}
+	ReactDOM = Asset{
+		Path:      "react-dom@17.0.2/umd/react-dom.production.min.js",
+		Integrity: "sha256-nbMykgB6tsOFJ7OdVmPpdqMFVk4ZsqWocT6issAPUF0=",
+	}
+)
+
+// All lists every asset of the playground page
+var All = []Asset{GraphiQLCSS, GraphiQLJS, React, ReactDOM}
+
+// AltairVersion is the version of altair-static the Altair page is pinned to
+const AltairVersion = "5.0.5"
+
+// AltairBase is the directory below CDN the Altair page loads its files from
+const AltairBase = "altair-static@" + AltairVersion + "/build/dist/"
+
+var (
+	AltairCSS = Asset{
+		Path:      AltairBase + "styles.css",
+		Integrity: "sha256-kZ35e5mdMYN5ALEbnsrA2CLn85Oe4hBodfsih9BqNxs=",
+	}
+	AltairMain = Asset{
+		Path:      AltairBase + "main.js",
+		Integrity: "sha256-nWdVTcGTlBDV1L04UQnqod+AJedzBCnKHv6Ct65liHE=",
+	}
+	AltairPolyfills = Asset{
+		Path:      AltairBase + "polyfills.js",
+		Integrity: "sha256-1aVEg2sROcCQ/RxU3AlcPaRZhZdIWA92q2M+mdd/R4c=",
+	}
+	AltairRuntime = Asset{
+		Path:      AltairBase + "runtime.js",
+		Integrity: "sha256-cK2XhXqQr0WS1Z5eKNdac0rJxTD6miC3ubd+aEVMQDk=",
+	}
+)
+
+// ApolloSandboxCDN is the root the Apollo Sandbox page loads its script from, the embeddable sandbox
+// is not published to npm
+const ApolloSandboxCDN = "https://embeddable-sandbox.cdn.apollographql.com/"
+
+// ApolloSandbox is the script of the embeddable Apollo Sandbox, below ApolloSandboxCDN
+var ApolloSandbox = Asset{
+	Path:      "58165cf7452dbad480c7cb85e7acba085b3bac1d/embeddable-sandbox.umd.production.min.js",
+	Integrity: "sha256-/E4VNgAWFmbNLyXACSYoqsDAj68jC1sCMSQ0cDjf4YM=",
+}
+
+// VoyagerVersion is the version of graphql-voyager the Voyager page is pinned to
+const VoyagerVersion = "2.0.0"
+
+// The files of the Voyager page, the standalone bundle includes React. Their integrity is not pinned
+// yet, the page requires it in the config until it is.
+var (
+	VoyagerCSS = Asset{
+		Path: "graphql-voyager@" + VoyagerVersion + "/dist/voyager.css",
+	}
+	VoyagerJS = Asset{
+		Path: "graphql-voyager@" + VoyagerVersion + "/dist/voyager.standalone.js",
+	}
+)
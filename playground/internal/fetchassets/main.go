@@ -0,0 +1,58 @@
+// Command fetchassets downloads the playground assets from the CDN into the directory embedded by
+// the playground package, checking each of them against its subresource integrity.
+//
+//	go run ./internal/fetchassets -dir assets
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/NickTaporuk/fiber-gqlgen/playground/internal/assets"
+)
+
+func main() {
+	dir := flag.String("dir", "assets", "directory the assets are written to")
+	flag.Parse()
+
+	for _, asset := range assets.All {
+		if err := fetch(*dir, asset); err != nil {
+			log.Fatalf("%s: %s", asset.Path, err)
+		}
+	}
+}
+
+func fetch(dir string, asset assets.Asset) error {
+	resp, err := http.Get(assets.CDN + asset.Path)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	sum := sha256.Sum256(b)
+	if integrity := "sha256-" + base64.StdEncoding.EncodeToString(sum[:]); integrity != asset.Integrity {
+		return fmt.Errorf("integrity %s does not match %s", integrity, asset.Integrity)
+	}
+
+	path := filepath.Join(dir, filepath.FromSlash(asset.Path))
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, b, 0o644)
+}
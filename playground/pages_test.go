@@ -0,0 +1,108 @@
+package playground
+
+import (
+	"html"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAltair(t *testing.T) {
+	page := html.UnescapeString(serve(t, Altair(Config{
+		Title:                "Altair",
+		Endpoint:             "/graphql",
+		SubscriptionEndpoint: "wss://ws.example.com/graphql",
+		InitialQuery:         "{ name }",
+		DefaultHeaders:       map[string]string{"X-Token": "a"},
+	})))
+	assert.Contains(t, page, `<title>Altair</title>`)
+	assert.Contains(t, page, `<base href="https://cdn.jsdelivr.net/npm/altair-static@5.0.5/build/dist/">`)
+	assert.Contains(t, page, `href="https://cdn.jsdelivr.net/npm/altair-static@5.0.5/build/dist/styles.css"
+		integrity="sha256-kZ35e5mdMYN5ALEbnsrA2CLn85Oe4hBodfsih9BqNxs="`)
+	assert.Contains(t, page, `src="https://cdn.jsdelivr.net/npm/altair-static@5.0.5/build/dist/main.js"
+		integrity="sha256-nWdVTcGTlBDV1L04UQnqod+AJedzBCnKHv6Ct65liHE="`)
+	assert.Contains(t, page, `const url = location.protocol + '//' + location.host + "/graphql";`)
+	assert.Contains(t, page, `const subscriptionUrl = "wss://ws.example.com/graphql";`)
+	assert.Contains(t, page, `const options = {"initialHeaders":{"X-Token":"a"},"initialQuery":"{ name }"};`)
+}
+
+func TestApolloSandbox(t *testing.T) {
+	page := html.UnescapeString(serve(t, ApolloSandbox(Config{
+		InitialQuery:     "{ name }",
+		InitialVariables: `{"id": 1}`,
+	})))
+	assert.Contains(t, page, `src="https://embeddable-sandbox.cdn.apollographql.com/58165cf7452dbad480c7cb85e7acba085b3bac1d/embeddable-sandbox.umd.production.min.js"
+		integrity="sha256-/E4VNgAWFmbNLyXACSYoqsDAj68jC1sCMSQ0cDjf4YM="`)
+	assert.Contains(t, page, `const url = location.protocol + '//' + location.host + "/query";`)
+	assert.Contains(t, page, `const initialState = {"document":"{ name }","includeCookies":true,"pollForSchemaUpdates":false,"variables":{"id":1}};`)
+
+	assert.PanicsWithValue(t, "playground: the initial variables of Apollo Sandbox are not a JSON object: unexpected end of JSON input", func() {
+		ApolloSandbox(Config{InitialVariables: `{"id": 1`})
+	})
+}
+
+// voyagerIntegrity is the config of the Voyager integrity used in tests
+var voyagerIntegrity = Config{VoyagerCSSIntegrity: "sha256-css", VoyagerJSIntegrity: "sha256-js"}
+
+func TestVoyager(t *testing.T) {
+	cfg := voyagerIntegrity
+	cfg.DefaultHeaders = map[string]string{"Authorization": "Bearer token"}
+	page := serve(t, Voyager(cfg))
+	assert.Contains(t, page, `href="https://cdn.jsdelivr.net/npm/graphql-voyager@2.0.0/dist/voyager.css"
+		integrity="sha256-css"`)
+	assert.Contains(t, page, `src="https://cdn.jsdelivr.net/npm/graphql-voyager@2.0.0/dist/voyager.standalone.js"
+		integrity="sha256-js"`)
+	assert.Contains(t, page, `const headers = {"Authorization":"Bearer token"};`)
+
+	page = serve(t, Voyager(voyagerIntegrity))
+	assert.Contains(t, page, `const headers = {};`)
+
+	if ConfigDefault.VoyagerCSSIntegrity == "" || ConfigDefault.VoyagerJSIntegrity == "" {
+		assert.PanicsWithValue(t, "playground: Voyager is not loaded without subresource integrity, set VoyagerCSSIntegrity and VoyagerJSIntegrity", func() {
+			Voyager(Config{VoyagerCSSIntegrity: "sha256-css"})
+		})
+	}
+}
+
+func TestPages(t *testing.T) {
+	pages := map[string]func(...Config) fiber.Handler{
+		"Altair":         Altair,
+		"Apollo Sandbox": ApolloSandbox,
+		"Voyager": func(config ...Config) fiber.Handler {
+			config[0].VoyagerCSSIntegrity = voyagerIntegrity.VoyagerCSSIntegrity
+			config[0].VoyagerJSIntegrity = voyagerIntegrity.VoyagerJSIntegrity
+			return Voyager(config...)
+		},
+	}
+	for name, h := range pages {
+		t.Run(name, func(t *testing.T) {
+			app := fiber.New()
+			app.Get("/playground", h(Config{Next: func(c *fiber.Ctx) bool { return c.Query("skip") != "" }}), func(c *fiber.Ctx) error {
+				return c.SendStatus(fiber.StatusTeapot)
+			})
+
+			resp, err := app.Test(httptest.NewRequest("GET", "/playground", nil))
+			require.NoError(t, err)
+			assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+			assert.Equal(t, "text/html", resp.Header.Get("Content-Type"))
+
+			resp, err = app.Test(httptest.NewRequest("GET", "/playground?skip=1", nil))
+			require.NoError(t, err)
+			assert.Equal(t, fiber.StatusTeapot, resp.StatusCode)
+
+			page := serve(t, h(Config{Nonce: func(c *fiber.Ctx) string { return "abc" }}))
+			assert.NotContains(t, page, "<script>", "every inline script has the nonce")
+			assert.NotContains(t, page, "<style>", "every inline style has the nonce")
+			assert.NotContains(t, page, "style=", "nonces do not apply to style attributes")
+			assert.Contains(t, page, `
+		nonce="abc"
+		crossorigin="anonymous"
+	></script>`)
+
+			assert.PanicsWithValue(t, "playground: "+name+" is only loaded from the CDN", func() { h(Config{Assets: AssetsEmbedded}) })
+		})
+	}
+}
@@ -2,8 +2,15 @@ package playground
 
 import (
 	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
 	"html/template"
+	"strconv"
+	"strings"
 
+	"github.com/NickTaporuk/fiber-gqlgen/playground/internal/assets"
 	"github.com/gofiber/fiber/v2"
 )
 
@@ -11,74 +18,249 @@ var page = template.Must(template.New("graphiql").Parse(`<!DOCTYPE html>
 <html>
   <head>
     <title>{{.title}}</title>
+{{- range .styles}}
     <link
 		rel="stylesheet"
-		href="https://cdn.jsdelivr.net/npm/graphiql@{{.version}}/graphiql.min.css"
-		integrity="{{.cssSRI}}"
+		href="{{.URL}}"
+		{{- with .Integrity}}
+		integrity="{{.}}"
+		{{- end}}
+		{{- with $.nonce}}
+		nonce="{{.}}"
+		{{- end}}
 		crossorigin="anonymous"
 	/>
+{{- end}}
+    <style{{with .nonce}} nonce="{{.}}"{{end}}>
+      body {
+        margin: 0;
+      }
+      #graphiql {
+        height: 100vh;
+      }
+    </style>
   </head>
-  <body style="margin: 0;">
-    <div id="graphiql" style="height: 100vh;"></div>
+  <body>
+    <div id="graphiql"></div>
+{{- range .scripts}}
 	<script
-		src="https://cdn.jsdelivr.net/npm/react@17.0.2/umd/react.production.min.js"
-		integrity="{{.reactSRI}}"
+		src="{{.URL}}"
+		{{- with .Integrity}}
+		integrity="{{.}}"
+		{{- end}}
+		{{- with $.nonce}}
+		nonce="{{.}}"
+		{{- end}}
 		crossorigin="anonymous"
 	></script>
-	<script
-		src="https://cdn.jsdelivr.net/npm/react-dom@17.0.2/umd/react-dom.production.min.js"
-		integrity="{{.reactDOMSRI}}"
-		crossorigin="anonymous"
-	></script>
-	<script
-		src="https://cdn.jsdelivr.net/npm/graphiql@{{.version}}/graphiql.min.js"
-		integrity="{{.jsSRI}}"
-		crossorigin="anonymous"
-	></script>
-    <script>
+{{- end}}
+    <script{{with .nonce}} nonce="{{.}}"{{end}}>
       const url = location.protocol + '//' + location.host + '{{.endpoint}}';
       const wsProto = location.protocol == 'https:' ? 'wss:' : 'ws:';
-      const subscriptionUrl = wsProto + '//' + location.host + '{{.endpoint}}';
+      const subscriptionUrl = {{if .subscriptionAbsolute}}{{.subscriptionEndpoint}}{{else}}wsProto + '//' + location.host + {{.subscriptionEndpoint}}{{end}};
       const fetcher = GraphiQL.createFetcher({ url, subscriptionUrl });
-      ReactDOM.render(
-        React.createElement(GraphiQL, {
+      const props = {{.props}};
+{{- if .explorer}}
+      function GraphiQLWithExplorer() {
+        const [query, setQuery] = React.useState(props.defaultQuery);
+        const explorer = GraphiQLPluginExplorer.useExplorerPlugin({ query: query, onEdit: setQuery });
+        return React.createElement(GraphiQL, Object.assign({}, props, {
           fetcher: fetcher,
-          headerEditorEnabled: true,
-          shouldPersistHeaders: true
-        }),
+          query: query,
+          onEditQuery: setQuery,
+          plugins: [explorer]
+        }));
+      }
+      ReactDOM.render(
+        React.createElement(GraphiQLWithExplorer),
         document.getElementById('graphiql'),
       );
+{{- else}}
+      ReactDOM.render(
+        React.createElement(GraphiQL, Object.assign({ fetcher: fetcher }, props)),
+        document.getElementById('graphiql'),
+      );
+{{- end}}
     </script>
   </body>
 </html>
 `))
 
+// pageAsset is a stylesheet or script of the page
+type pageAsset struct {
+	URL       string
+	Integrity string
+}
+
+// New creates a handler serving the GraphiQL playground page. In the AssetsEmbedded mode it also serves
+// the assets of the page and panics when they are not embedded in the binary.
+//
+// Every value of the config is escaped by html/template for the context it is rendered in.
 func New(config ...Config) fiber.Handler {
 	// Set default config
 	cfg := configDefault(config...)
 
+	major := majorVersion(cfg.GraphiQLVersion)
+	if len(cfg.Tabs) > 0 && major < 2 {
+		panic("playground: tabs require GraphiQL 2 or later")
+	}
+	if cfg.ExplorerPluginVersion != "" && major < 2 {
+		panic("playground: the explorer plugin requires GraphiQL 2 or later")
+	}
+
+	if cfg.Assets == AssetsEmbedded {
+		if cfg.GraphiQLVersion != assets.GraphiQLVersion || cfg.ExplorerPluginVersion != "" {
+			panic(fmt.Sprintf("playground: only GraphiQL %s without plugins is embedded", assets.GraphiQLVersion))
+		}
+		if err := verifyAssets(assetsFS, assets.All); err != nil {
+			panic(err)
+		}
+	}
+
+	props, err := graphiQLProps(cfg, major)
+	if err != nil {
+		panic(err)
+	}
+
 	return func(c *fiber.Ctx) error {
 		// Don't execute middleware if Next returns true
 		if cfg.Next != nil && cfg.Next(c) {
 			return c.Next()
 		}
 
-		c.Set("content-type", "text/html")
-
-		body := new(bytes.Buffer)
-		err := page.Execute(body, map[string]string{
-			"title":       cfg.Title,
-			"endpoint":    cfg.Endpoint,
-			"version":     "1.5.16",
-			"cssSRI":      "sha256-HADQowUuFum02+Ckkv5Yu5ygRoLllHZqg0TFZXY7NHI=",
-			"jsSRI":       "sha256-uHp12yvpXC4PC9+6JmITxKuLYwjlW9crq9ywPE5Rxco=",
-			"reactSRI":    "sha256-Ipu/TQ50iCCVZBUsZyNJfxrDk0E2yhaEIz0vqI+kFG8=",
-			"reactDOMSRI": "sha256-nbMykgB6tsOFJ7OdVmPpdqMFVk4ZsqWocT6issAPUF0=",
+		if cfg.Assets == AssetsEmbedded {
+			if ok, err := serveAsset(c); ok {
+				return err
+			}
+		}
+
+		styles, scripts := pageAssets(cfg, c.Path())
+		return execute(c, cfg, page, map[string]interface{}{
+			"title":                cfg.Title,
+			"endpoint":             cfg.Endpoint,
+			"subscriptionEndpoint": cfg.SubscriptionEndpoint,
+			"subscriptionAbsolute": isWebSocketURL(cfg.SubscriptionEndpoint),
+			"styles":               styles,
+			"scripts":              scripts,
+			"props":                props,
+			"explorer":             cfg.ExplorerPluginVersion != "",
 		})
+	}
+}
+
+// execute renders t with data as the html response, along with the nonce and the Content-Security-Policy
+// of the request
+func execute(c *fiber.Ctx, cfg Config, t *template.Template, data map[string]interface{}) error {
+	c.Set("content-type", "text/html")
+
+	var nonce string
+	if cfg.Nonce != nil {
+		nonce = cfg.Nonce(c)
+	}
+	data["nonce"] = nonce
+	if cfg.ContentSecurityPolicy != "" {
+		c.Set(fiber.HeaderContentSecurityPolicy, strings.ReplaceAll(cfg.ContentSecurityPolicy, "{nonce}", nonce))
+	}
+
+	body := new(bytes.Buffer)
+	if err := t.Execute(body, data); err != nil {
+		return err
+	}
+
+	return c.SendString(body.String())
+}
+
+// GenerateNonce returns a random nonce for the Content-Security-Policy of a page
+func GenerateNonce(c *fiber.Ctx) string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return base64.StdEncoding.EncodeToString(b)
+}
+
+// cdnOnly panics when cfg embeds the assets of a page that is only loaded from the CDN, only the
+// GraphiQL assets are embedded
+func cdnOnly(cfg Config, name string) {
+	if cfg.Assets == AssetsEmbedded {
+		panic(fmt.Sprintf("playground: %s is only loaded from the CDN", name))
+	}
+}
+
+// isWebSocketURL reports whether endpoint is an absolute ws:// or wss:// url
+func isWebSocketURL(endpoint string) bool {
+	return strings.HasPrefix(endpoint, "ws://") || strings.HasPrefix(endpoint, "wss://")
+}
+
+// pageAssets returns the stylesheets and the scripts of a page served at base
+func pageAssets(cfg Config, base string) (styles, scripts []pageAsset) {
+	if cfg.Assets == AssetsEmbedded {
+		for _, asset := range []assets.Asset{assets.GraphiQLCSS} {
+			styles = append(styles, pageAsset{URL: assetURL(cfg.Assets, base, asset.Path), Integrity: asset.Integrity})
+		}
+		for _, asset := range []assets.Asset{assets.React, assets.ReactDOM, assets.GraphiQLJS} {
+			scripts = append(scripts, pageAsset{URL: assetURL(cfg.Assets, base, asset.Path), Integrity: asset.Integrity})
+		}
+		return styles, scripts
+	}
+
+	graphiql := "graphiql@" + cfg.GraphiQLVersion + "/"
+	styles = []pageAsset{{URL: assets.CDN + graphiql + "graphiql.min.css", Integrity: cfg.GraphiQLCSSIntegrity}}
+	scripts = []pageAsset{
+		cdnAsset(assets.React),
+		cdnAsset(assets.ReactDOM),
+		{URL: assets.CDN + graphiql + "graphiql.min.js", Integrity: cfg.GraphiQLJSIntegrity},
+	}
+
+	if cfg.ExplorerPluginVersion != "" {
+		explorer := "@graphiql/plugin-explorer@" + cfg.ExplorerPluginVersion + "/dist/"
+		styles = append(styles, pageAsset{URL: assets.CDN + explorer + "style.css", Integrity: cfg.ExplorerPluginCSSIntegrity})
+		scripts = append(scripts, pageAsset{URL: assets.CDN + explorer + "graphiql-plugin-explorer.umd.js", Integrity: cfg.ExplorerPluginJSIntegrity})
+	}
+
+	return styles, scripts
+}
+
+// graphiQLProps returns the props of the GraphiQL component, the names of some of them changed with
+// GraphiQL 2
+func graphiQLProps(cfg Config, major int) (map[string]interface{}, error) {
+	props := map[string]interface{}{
+		"shouldPersistHeaders": true,
+	}
+	if major < 2 {
+		props["headerEditorEnabled"] = true
+	} else {
+		props["isHeadersEditorEnabled"] = true
+	}
+
+	if cfg.InitialQuery != "" {
+		props["defaultQuery"] = cfg.InitialQuery
+	}
+	if cfg.InitialVariables != "" {
+		props["variables"] = cfg.InitialVariables
+	}
+
+	if len(cfg.DefaultHeaders) > 0 {
+		headers, err := json.MarshalIndent(cfg.DefaultHeaders, "", "  ")
 		if err != nil {
-			return err
+			return nil, err
+		}
+		if major < 2 {
+			props["headers"] = string(headers)
+		} else {
+			props["defaultHeaders"] = string(headers)
 		}
+	}
 
-		return c.SendString(body.String())
+	if len(cfg.Tabs) > 0 {
+		props["defaultTabs"] = cfg.Tabs
 	}
+
+	return props, nil
+}
+
+// majorVersion returns the major version of a semantic version, 0 when it can not be parsed
+func majorVersion(version string) int {
+	major, _ := strconv.Atoi(strings.SplitN(version, ".", 2)[0])
+	return major
 }
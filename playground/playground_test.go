@@ -0,0 +1,126 @@
+package playground
+
+import (
+	"html"
+	"io/ioutil"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func render(t *testing.T, cfg Config) string {
+	t.Helper()
+	return serve(t, New(cfg))
+}
+
+func serve(t *testing.T, h fiber.Handler) string {
+	t.Helper()
+	app := fiber.New()
+	app.Get("/playground", h)
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/playground", nil))
+	require.NoError(t, err)
+	b, err := ioutil.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return string(b)
+}
+
+func TestNew(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		page := render(t, Config{})
+		assert.Contains(t, page, `const subscriptionUrl = wsProto + '//' + location.host + "/query";`)
+		assert.Contains(t, page, `const props = {"headerEditorEnabled":true,"shouldPersistHeaders":true};`)
+		assert.Contains(t, page, `React.createElement(GraphiQL, Object.assign({ fetcher: fetcher }, props))`)
+	})
+
+	t.Run("subscription endpoint", func(t *testing.T) {
+		page := render(t, Config{Endpoint: "/graphql", SubscriptionEndpoint: "/subscriptions"})
+		assert.Contains(t, page, `location.host + '\/graphql'`)
+		assert.Contains(t, page, `const subscriptionUrl = wsProto + '//' + location.host + "/subscriptions";`)
+
+		page = render(t, Config{SubscriptionEndpoint: "wss://ws.example.com/graphql"})
+		assert.Contains(t, page, `const subscriptionUrl = "wss://ws.example.com/graphql";`)
+	})
+
+	t.Run("graphiql version", func(t *testing.T) {
+		page := render(t, Config{GraphiQLVersion: "2.4.7", GraphiQLJSIntegrity: "sha384-js"})
+		assert.Contains(t, page, `href="https://cdn.jsdelivr.net/npm/graphiql@2.4.7/graphiql.min.css"
+		crossorigin="anonymous"`, "the default integrity belongs to the default version")
+		assert.Contains(t, page, `src="https://cdn.jsdelivr.net/npm/graphiql@2.4.7/graphiql.min.js"
+		integrity="sha384-js"`)
+		assert.Contains(t, page, `"isHeadersEditorEnabled":true`)
+	})
+
+	t.Run("editor state", func(t *testing.T) {
+		page := render(t, Config{
+			GraphiQLVersion:  "2.4.7",
+			DefaultHeaders:   map[string]string{"Authorization": "Bearer token"},
+			InitialQuery:     "{ name } </script><script>alert(1)</script>",
+			InitialVariables: `{"id": 1}`,
+			Tabs:             []Tab{{Query: "{ a }"}, {Query: "{ b }", Variables: `{"b": 2}`}},
+		})
+		assert.NotContains(t, page, "</script><script>alert(1)")
+		assert.Contains(t, page, `"defaultQuery":"{ name } \u003c/script\u003e\u003cscript\u003ealert(1)\u003c/script\u003e"`)
+		assert.Contains(t, page, `"variables":"{\"id\": 1}"`)
+		assert.Contains(t, page, `"defaultHeaders":"{\n  \"Authorization\": \"Bearer token\"\n}"`)
+		assert.Contains(t, page, `"defaultTabs":[{"query":"{ a }"},{"query":"{ b }","variables":"{\"b\": 2}"}]`)
+	})
+
+	t.Run("headers of graphiql 1", func(t *testing.T) {
+		page := render(t, Config{DefaultHeaders: map[string]string{"X-Token": "a"}})
+		assert.Contains(t, page, `"headers":"{\n  \"X-Token\": \"a\"\n}"`)
+	})
+
+	t.Run("explorer plugin", func(t *testing.T) {
+		page := html.UnescapeString(render(t, Config{GraphiQLVersion: "2.4.7", ExplorerPluginVersion: "0.1.20", ExplorerPluginJSIntegrity: "sha384-explorer"}))
+		assert.Contains(t, page, `href="https://cdn.jsdelivr.net/npm/@graphiql/plugin-explorer@0.1.20/dist/style.css"`)
+		assert.Contains(t, page, `src="https://cdn.jsdelivr.net/npm/@graphiql/plugin-explorer@0.1.20/dist/graphiql-plugin-explorer.umd.js"
+		integrity="sha384-explorer"`)
+		assert.Contains(t, page, `React.createElement(GraphiQLWithExplorer)`)
+	})
+
+	t.Run("nonce", func(t *testing.T) {
+		page := render(t, Config{Nonce: func(c *fiber.Ctx) string { return "abc" }})
+		assert.Contains(t, page, `href="https://cdn.jsdelivr.net/npm/graphiql@1.5.16/graphiql.min.css"`)
+		assert.Equal(t, 4, strings.Count(page, `
+		nonce="abc"
+		crossorigin="anonymous"`), "every stylesheet and script")
+		assert.Contains(t, page, `<script nonce="abc">`)
+		assert.Contains(t, page, `<style nonce="abc">`)
+		assert.NotContains(t, page, "style=", "nonces do not apply to style attributes")
+	})
+
+	t.Run("content security policy", func(t *testing.T) {
+		app := fiber.New()
+		app.Get("/playground", New(Config{ContentSecurityPolicy: "script-src 'nonce-{nonce}'; style-src 'nonce-{nonce}'"}))
+
+		policies := map[string]bool{}
+		for i := 0; i < 2; i++ {
+			resp, err := app.Test(httptest.NewRequest("GET", "/playground", nil))
+			require.NoError(t, err)
+			b, err := ioutil.ReadAll(resp.Body)
+			require.NoError(t, err)
+
+			policy := resp.Header.Get("Content-Security-Policy")
+			m := regexp.MustCompile(`^script-src 'nonce-([A-Za-z0-9+/=]{24})'; style-src 'nonce-([A-Za-z0-9+/=]{24})'$`).FindStringSubmatch(policy)
+			require.NotNil(t, m, policy)
+			assert.Equal(t, m[1], m[2])
+			assert.Contains(t, html.UnescapeString(string(b)), `<script nonce="`+m[1]+`">`)
+			policies[policy] = true
+		}
+		assert.Len(t, policies, 2, "a nonce per request")
+
+		page := render(t, Config{ContentSecurityPolicy: "default-src 'self'"})
+		assert.NotContains(t, page, "nonce")
+	})
+
+	t.Run("features of graphiql 2", func(t *testing.T) {
+		assert.PanicsWithValue(t, "playground: tabs require GraphiQL 2 or later", func() { New(Config{Tabs: []Tab{{Query: "{ a }"}}}) })
+		assert.PanicsWithValue(t, "playground: the explorer plugin requires GraphiQL 2 or later", func() { New(Config{ExplorerPluginVersion: "0.1.20"}) })
+	})
+}
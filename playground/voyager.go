@@ -0,0 +1,102 @@
+package playground
+
+import (
+	"html/template"
+
+	"github.com/NickTaporuk/fiber-gqlgen/playground/internal/assets"
+	"github.com/gofiber/fiber/v2"
+)
+
+var voyagerPage = template.Must(template.New("voyager").Parse(`<!DOCTYPE html>
+<html>
+  <head>
+    <meta charset="utf-8">
+    <title>{{.title}}</title>
+    <meta name="viewport" content="width=device-width,initial-scale=1">
+{{- range .styles}}
+    <link
+		rel="stylesheet"
+		href="{{.URL}}"
+		{{- with .Integrity}}
+		integrity="{{.}}"
+		{{- end}}
+		{{- with $.nonce}}
+		nonce="{{.}}"
+		{{- end}}
+		crossorigin="anonymous"
+	/>
+{{- end}}
+    <style{{with .nonce}} nonce="{{.}}"{{end}}>
+      body {
+        margin: 0;
+      }
+      #voyager {
+        height: 100vh;
+      }
+    </style>
+  </head>
+  <body>
+    <div id="voyager"></div>
+{{- range .scripts}}
+	<script
+		src="{{.URL}}"
+		{{- with .Integrity}}
+		integrity="{{.}}"
+		{{- end}}
+		{{- with $.nonce}}
+		nonce="{{.}}"
+		{{- end}}
+		crossorigin="anonymous"
+	></script>
+{{- end}}
+    <script type="module"{{with .nonce}} nonce="{{.}}"{{end}}>
+      const url = location.protocol + '//' + location.host + {{.endpoint}};
+      const headers = {{.headers}};
+      const response = await fetch(url, {
+        method: 'POST',
+        credentials: 'include',
+        headers: Object.assign({ 'Accept': 'application/json', 'Content-Type': 'application/json' }, headers),
+        body: JSON.stringify({ query: GraphQLVoyager.voyagerIntrospectionQuery }),
+      });
+      const introspection = await response.json();
+      GraphQLVoyager.renderVoyager(document.getElementById('voyager'), { introspection });
+    </script>
+  </body>
+</html>
+`))
+
+// Voyager creates a handler serving GraphQL Voyager, pinned to graphql-voyager 2.0.0 loaded from the CDN.
+// The page introspects the schema from Endpoint, so introspection must be allowed for its requests. It uses
+// Title, Endpoint, DefaultHeaders, VoyagerCSSIntegrity, VoyagerJSIntegrity and Next of the config, and panics
+// when the config embeds the assets or the integrity of a file is missing.
+func Voyager(config ...Config) fiber.Handler {
+	// Set default config
+	cfg := configDefault(config...)
+	cdnOnly(cfg, "Voyager")
+	if cfg.VoyagerCSSIntegrity == "" || cfg.VoyagerJSIntegrity == "" {
+		panic("playground: Voyager is not loaded without subresource integrity, set VoyagerCSSIntegrity and VoyagerJSIntegrity")
+	}
+
+	headers := cfg.DefaultHeaders
+	if headers == nil {
+		headers = map[string]string{}
+	}
+
+	styles := []pageAsset{{URL: assets.CDN + assets.VoyagerCSS.Path, Integrity: cfg.VoyagerCSSIntegrity}}
+	scripts := []pageAsset{{URL: assets.CDN + assets.VoyagerJS.Path, Integrity: cfg.VoyagerJSIntegrity}}
+
+	return func(c *fiber.Ctx) error {
+		// Don't execute middleware if Next returns true
+		if cfg.Next != nil && cfg.Next(c) {
+			return c.Next()
+		}
+
+		return execute(c, cfg, voyagerPage, map[string]interface{}{
+			"title":    cfg.Title,
+			"endpoint": cfg.Endpoint,
+			"styles":   styles,
+			"scripts":  scripts,
+			"headers":  headers,
+		})
+	}
+}
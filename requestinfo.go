@@ -0,0 +1,127 @@
+package fibergqlgen
+
+import (
+	"context"
+	"crypto/tls"
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+)
+
+type requestInfoKey struct{}
+
+// RequestInfo is a copy of the parts of a request resolvers commonly need, taken when the request
+// arrives. Fiber reuses *fiber.Ctx once the handler returns, so it must not be kept by resolvers that
+// run in other goroutines, like subscriptions and dataloaders. RequestInfo holds no reference to the
+// request and can not be modified, so it is safe to use from any goroutine for as long as needed.
+type RequestInfo struct {
+	method  string
+	path    string
+	headers http.Header
+	cookies map[string]string
+	ip      string
+	tls     *tls.ConnectionState
+	locals  map[string]interface{}
+}
+
+// NewRequestInfo copies the request of c, along with the values stored in c.Locals under the given
+// keys. The local values themselves are not copied, only values that are safe to share should be listed.
+func NewRequestInfo(c *fiber.Ctx, locals ...string) *RequestInfo {
+	info := &RequestInfo{
+		method:  utils.CopyString(c.Method()),
+		path:    utils.CopyString(c.Path()),
+		headers: http.Header{},
+		cookies: map[string]string{},
+		ip:      utils.CopyString(c.IP()),
+		locals:  make(map[string]interface{}, len(locals)),
+	}
+
+	c.Request().Header.VisitAll(func(key, value []byte) {
+		info.headers.Add(string(key), string(value))
+	})
+	c.Request().Header.VisitAllCookie(func(key, value []byte) {
+		info.cookies[string(key)] = string(value)
+	})
+	if state := c.Context().TLSConnectionState(); state != nil {
+		copied := *state
+		info.tls = &copied
+	}
+	for _, key := range locals {
+		if value := c.Locals(key); value != nil {
+			info.locals[key] = value
+		}
+	}
+
+	return info
+}
+
+// WithRequestInfo returns a copy of ctx carrying info
+func WithRequestInfo(ctx context.Context, info *RequestInfo) context.Context {
+	return context.WithValue(ctx, requestInfoKey{}, info)
+}
+
+// ForContext returns the RequestInfo of the request an operation was started by, or nil when there is none
+func ForContext(ctx context.Context) *RequestInfo {
+	info, _ := ctx.Value(requestInfoKey{}).(*RequestInfo)
+	return info
+}
+
+// Method returns the HTTP method of the request
+func (r *RequestInfo) Method() string {
+	return r.method
+}
+
+// Path returns the path of the request
+func (r *RequestInfo) Path() string {
+	return r.path
+}
+
+// Header returns the first value of the request header key
+func (r *RequestInfo) Header(key string) string {
+	return r.headers.Get(key)
+}
+
+// Headers returns a copy of all request headers
+func (r *RequestInfo) Headers() http.Header {
+	return r.headers.Clone()
+}
+
+// Cookie returns the value of the request cookie name
+func (r *RequestInfo) Cookie(name string) string {
+	return r.cookies[name]
+}
+
+// Cookies returns a copy of all request cookies by name
+func (r *RequestInfo) Cookies() map[string]string {
+	cookies := make(map[string]string, len(r.cookies))
+	for name, value := range r.cookies {
+		cookies[name] = value
+	}
+	return cookies
+}
+
+// IP returns the remote IP address of the request
+func (r *RequestInfo) IP() string {
+	return r.ip
+}
+
+// TLS reports whether the request was received over TLS
+func (r *RequestInfo) TLS() bool {
+	return r.tls != nil
+}
+
+// TLSConnectionState returns a copy of the TLS connection state, or nil for requests received without TLS
+func (r *RequestInfo) TLSConnectionState() *tls.ConnectionState {
+	if r.tls == nil {
+		return nil
+	}
+	copied := *r.tls
+	return &copied
+}
+
+// Local returns the value stored in c.Locals under key, if key is in the allowlist the RequestInfo
+// was created with
+func (r *RequestInfo) Local(key string) interface{} {
+	return r.locals[key]
+}
@@ -0,0 +1,57 @@
+package fibergqlgen_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	fibergqlgen "github.com/NickTaporuk/fiber-gqlgen"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestInfo(t *testing.T) {
+	var info *fibergqlgen.RequestInfo
+
+	app := fiber.New()
+	app.Post("/graphql", func(c *fiber.Ctx) error {
+		c.Locals("user", "alice")
+		c.Locals("secret", "s3cr3t")
+		ctx := fibergqlgen.WithRequestInfo(context.Background(), fibergqlgen.NewRequestInfo(c, "user"))
+		info = fibergqlgen.ForContext(ctx)
+		return c.SendStatus(http.StatusNoContent)
+	})
+
+	req := httptest.NewRequest("POST", "/graphql?x=1", nil)
+	req.Header.Set("Authorization", "Bearer token")
+	req.Header.Add("X-Multi", "a")
+	req.Header.Add("X-Multi", "b")
+	req.AddCookie(&http.Cookie{Name: "session", Value: "abc"})
+	_, err := app.Test(req)
+	require.NoError(t, err)
+
+	// fiber has reused the ctx by now, the snapshot must be unaffected
+	_, err = app.Test(httptest.NewRequest("GET", "/other", nil))
+	require.NoError(t, err)
+
+	require.NotNil(t, info)
+	assert.Equal(t, "POST", info.Method())
+	assert.Equal(t, "/graphql", info.Path())
+	assert.Equal(t, "Bearer token", info.Header("authorization"))
+	assert.Equal(t, []string{"a", "b"}, info.Headers().Values("X-Multi"))
+	assert.Equal(t, "abc", info.Cookie("session"))
+	assert.Equal(t, map[string]string{"session": "abc"}, info.Cookies())
+	assert.Equal(t, "0.0.0.0", info.IP())
+	assert.False(t, info.TLS())
+	assert.Nil(t, info.TLSConnectionState())
+	assert.Equal(t, "alice", info.Local("user"))
+	assert.Nil(t, info.Local("secret"), "only allowlisted locals are copied")
+
+	headers := info.Headers()
+	headers.Set("Authorization", "changed")
+	assert.Equal(t, "Bearer token", info.Header("Authorization"), "accessors return copies")
+
+	assert.Nil(t, fibergqlgen.ForContext(context.Background()))
+}
@@ -0,0 +1,119 @@
+package fibergqlgen
+
+import (
+	"context"
+	"net/http"
+	"sync"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type responseKey struct{}
+
+// Response collects the headers, cookies and status resolvers and extensions want on the HTTP response of
+// the current request. The transports apply it right before the body is written, streaming transports once
+// the first response is resolved, or once a subscription is subscribed. Changes made after that, eg while the
+// rest of a stream is resolved, are ignored.
+//
+// When several resolvers write to it:
+//   - SetHeader replaces the values of earlier SetHeader and AddHeader calls for that header, AddHeader appends
+//   - a cookie replaces an earlier one with the same name, fasthttp keeps a single cookie per name
+//   - the highest status code wins, so an error status is never masked by a success status
+//
+// Content-Type belongs to the transport and can not be changed. All methods are safe for concurrent use and do
+// nothing on a nil Response.
+type Response struct {
+	mu      sync.Mutex
+	headers http.Header
+	cookies []*fiber.Cookie
+	status  int
+}
+
+// WithResponse returns a copy of ctx carrying a new, empty Response
+func WithResponse(ctx context.Context) context.Context {
+	return context.WithValue(ctx, responseKey{}, &Response{headers: http.Header{}})
+}
+
+// ResponseFromContext returns the Response of the request an operation was started by, or nil when there is none
+func ResponseFromContext(ctx context.Context) *Response {
+	r, _ := ctx.Value(responseKey{}).(*Response)
+	return r
+}
+
+// SetHeader sets the response header key to value, replacing the values collected before
+func (r *Response) SetHeader(key, value string) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.headers.Set(key, value)
+}
+
+// AddHeader adds value to the response header key
+func (r *Response) AddHeader(key, value string) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.headers.Add(key, value)
+}
+
+// SetCookie sets a cookie on the response
+func (r *Response) SetCookie(cookie *fiber.Cookie) {
+	if r == nil || cookie == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	copied := *cookie
+	for i, c := range r.cookies {
+		if c.Name == cookie.Name {
+			r.cookies[i] = &copied
+			return
+		}
+	}
+	r.cookies = append(r.cookies, &copied)
+}
+
+// SetStatus overrides the status code of the response, the highest code set wins
+func (r *Response) SetStatus(code int) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if code > r.status {
+		r.status = code
+	}
+}
+
+// Apply writes the collected headers, cookies and status to the response of c
+func (r *Response) Apply(c *fiber.Ctx) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for key, values := range r.headers {
+		if key == fiber.HeaderContentType {
+			continue
+		}
+		c.Response().Header.Del(key)
+		for _, value := range values {
+			c.Response().Header.Add(key, value)
+		}
+	}
+	for _, cookie := range r.cookies {
+		c.Cookie(cookie)
+	}
+	if r.status != 0 {
+		c.Status(r.status)
+	}
+}
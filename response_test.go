@@ -0,0 +1,75 @@
+package fibergqlgen_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	fibergqlgen "github.com/NickTaporuk/fiber-gqlgen"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResponse(t *testing.T) {
+	t.Run("merge rules", func(t *testing.T) {
+		app := fiber.New()
+		app.Get("/", func(c *fiber.Ctx) error {
+			c.Set("Content-Type", "application/json")
+			c.Set("X-Transport", "kept")
+			c.Status(http.StatusOK)
+
+			r := fibergqlgen.ResponseFromContext(fibergqlgen.WithResponse(context.Background()))
+			var wg sync.WaitGroup
+			for _, status := range []int{http.StatusCreated, http.StatusUnauthorized, http.StatusAccepted} {
+				wg.Add(1)
+				go func(status int) {
+					defer wg.Done()
+					r.SetStatus(status)
+				}(status)
+			}
+			wg.Wait()
+
+			r.AddHeader("X-Added", "a")
+			r.AddHeader("X-Added", "b")
+			r.AddHeader("Cache-Control", "public")
+			r.SetHeader("Cache-Control", "private")
+			r.SetHeader("Content-Type", "text/html")
+			r.SetCookie(&fiber.Cookie{Name: "session", Value: "first"})
+			r.SetCookie(&fiber.Cookie{Name: "session", Value: "second"})
+			r.SetCookie(&fiber.Cookie{Name: "theme", Value: "dark"})
+			r.Apply(c)
+
+			return c.SendString("{}")
+		})
+
+		resp, err := app.Test(httptest.NewRequest("GET", "/", nil))
+		require.NoError(t, err)
+
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+		assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+		assert.Equal(t, "kept", resp.Header.Get("X-Transport"))
+		assert.Equal(t, []string{"a", "b"}, resp.Header.Values("X-Added"))
+		assert.Equal(t, []string{"private"}, resp.Header.Values("Cache-Control"))
+
+		cookies := resp.Cookies()
+		require.Len(t, cookies, 2)
+		assert.Equal(t, "second", cookies[0].Value)
+		assert.Equal(t, "session", cookies[0].Name)
+		assert.Equal(t, "dark", cookies[1].Value)
+	})
+
+	t.Run("nil response", func(t *testing.T) {
+		r := fibergqlgen.ResponseFromContext(context.Background())
+		assert.Nil(t, r)
+
+		assert.NotPanics(t, func() {
+			r.SetHeader("X-Test", "1")
+			r.AddHeader("X-Test", "1")
+			r.SetCookie(&fiber.Cookie{Name: "a"})
+			r.SetStatus(http.StatusTeapot)
+		})
+	})
+}